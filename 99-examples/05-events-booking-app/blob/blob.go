@@ -0,0 +1,47 @@
+// Package blob stores uploaded files outside the database. Callers use the
+// BlobStore interface, FSStore keeps the files on the local disk.
+package blob
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+var (
+	ErrNotFound   = errors.New("blob not found")
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// BlobStore saves and reads files by key. Keys are slash separated paths like
+// "events/12/3f9a...", made of letters, digits, '-', '_' and '.'.
+// Implementations must be safe for concurrent use.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	// Open returns a seekable reader, so it can answer range requests.
+	Open(ctx context.Context, key string) (io.ReadSeekCloser, error)
+	// Delete succeeds when the key does not exist.
+	Delete(ctx context.Context, key string) error
+}
+
+// ValidateKey refuses keys that could leave the store, like "../x" or "/x".
+func ValidateKey(key string) error {
+	if key == "" || len(key) > 255 {
+		return ErrInvalidKey
+	}
+
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+		for _, r := range part {
+			ok := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.'
+			if !ok {
+				return ErrInvalidKey
+			}
+		}
+	}
+
+	return nil
+}
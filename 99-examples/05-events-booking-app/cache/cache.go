@@ -0,0 +1,115 @@
+// Package cache keeps read results in memory so hot queries don't hit SQLite on every request.
+// Callers use ReadThrough, the storage behind it is any Cache implementation;
+// LRU is the in-process one.
+package cache
+
+import (
+	"strconv"
+	"sync"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache stores values by key. Implementations must be safe for concurrent use
+// and may drop entries at any time (size limit, TTL).
+type Cache interface {
+	Get(key string) (any, bool)
+	Set(key string, value any)
+	Delete(key string)
+	DeletePrefix(prefix string)
+	Stats() Stats
+}
+
+type Stats struct {
+	Hits      int64 `json:"hits"`
+	Misses    int64 `json:"misses"`
+	Evictions int64 `json:"evictions"` // dropped to make room, not counting expired or invalidated entries
+	Entries   int   `json:"entries"`
+
+	// misses that waited for a load another request had already started
+	SharedLoads int64 `json:"shared_loads"`
+}
+
+// ReadThrough loads missing keys and stores the result. Concurrent misses of
+// the same key share one load, and a load that raced with an invalidation is
+// returned to its callers but not stored.
+type ReadThrough struct {
+	cache Cache
+	group singleflight.Group
+
+	mu          sync.Mutex
+	generation  int64 // bumped by every invalidation
+	sharedLoads int64
+}
+
+func NewReadThrough(c Cache) *ReadThrough {
+	return &ReadThrough{cache: c}
+}
+
+// Get returns the cached value or calls load. Errors are not cached.
+func (r *ReadThrough) Get(key string, load func() (any, error)) (any, error) {
+	value, ok := r.cache.Get(key)
+	if ok {
+		return value, nil
+	}
+
+	r.mu.Lock()
+	generation := r.generation
+	r.mu.Unlock()
+
+	// loads started before an invalidation are not joined by later misses
+	flightKey := strconv.FormatInt(generation, 10) + ":" + key
+
+	loaded := false
+	value, err, _ := r.group.Do(flightKey, func() (any, error) {
+		loaded = true
+		value, err := load()
+		if err != nil {
+			return nil, err
+		}
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if r.generation == generation {
+			r.cache.Set(key, value)
+		}
+
+		return value, nil
+	})
+
+	if !loaded {
+		r.mu.Lock()
+		r.sharedLoads++
+		r.mu.Unlock()
+	}
+
+	return value, err
+}
+
+func (r *ReadThrough) Invalidate(keys ...string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	for _, key := range keys {
+		r.cache.Delete(key)
+	}
+}
+
+func (r *ReadThrough) InvalidatePrefix(prefix string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.generation++
+	r.cache.DeletePrefix(prefix)
+}
+
+func (r *ReadThrough) Stats() Stats {
+	stats := r.cache.Stats()
+
+	r.mu.Lock()
+	stats.SharedLoads = r.sharedLoads
+	r.mu.Unlock()
+
+	return stats
+}
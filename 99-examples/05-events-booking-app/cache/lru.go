@@ -0,0 +1,119 @@
+package cache
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRU is an in-process Cache holding at most size entries, each for at most ttl.
+// When full, the least recently used entry makes room for the new one.
+type LRU struct {
+	size int
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List // most recently used first
+	stats Stats
+}
+
+type lruEntry struct {
+	key     string
+	value   any
+	expires time.Time
+}
+
+func NewLRU(size int, ttl time.Duration) *LRU {
+	if size < 1 {
+		size = 1
+	}
+
+	return &LRU{
+		size:  size,
+		ttl:   ttl,
+		items: map[string]*list.Element{},
+		order: list.New(),
+	}
+}
+
+func (l *LRU) Get(key string) (any, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if ok && time.Now().After(element.Value.(*lruEntry).expires) {
+		l.remove(element)
+		ok = false
+	}
+	if !ok {
+		l.stats.Misses++
+		return nil, false
+	}
+
+	l.stats.Hits++
+	l.order.MoveToFront(element)
+
+	return element.Value.(*lruEntry).value, true
+}
+
+func (l *LRU) Set(key string, value any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	expires := time.Now().Add(l.ttl)
+
+	element, ok := l.items[key]
+	if ok {
+		entry := element.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		l.order.MoveToFront(element)
+		return
+	}
+
+	for l.order.Len() >= l.size {
+		l.remove(l.order.Back())
+		l.stats.Evictions++
+	}
+
+	l.items[key] = l.order.PushFront(&lruEntry{key: key, value: value, expires: expires})
+}
+
+func (l *LRU) Delete(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	element, ok := l.items[key]
+	if ok {
+		l.remove(element)
+	}
+}
+
+// DeletePrefix walks every entry, fine for the few thousand entries an in-process cache holds.
+func (l *LRU) DeletePrefix(prefix string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for key, element := range l.items {
+		if strings.HasPrefix(key, prefix) {
+			l.remove(element)
+		}
+	}
+}
+
+func (l *LRU) Stats() Stats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	stats := l.stats
+	stats.Entries = l.order.Len()
+
+	return stats
+}
+
+func (l *LRU) remove(element *list.Element) {
+	l.order.Remove(element)
+	delete(l.items, element.Value.(*lruEntry).key)
+}
@@ -0,0 +1,187 @@
+// Package config reads the app settings from environment variables,
+// falling back to defaults that work for local development.
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Config struct {
+	Port     string
+	GRPCPort string // the gRPC API for internal services listens separately
+
+	// how long before an event registered users get reminded, e.g. "24h,1h"
+	ReminderOffsets  []time.Duration
+	ReminderInterval time.Duration
+
+	// log, file or webhook
+	Notifier         string
+	NotifyFile       string
+	NotifyWebhookURL string
+
+	JobConcurrency       int
+	JobVisibilityTimeout time.Duration
+	JobPollInterval      time.Duration
+
+	// event listings and details are cached in memory, 0 entries turns it off
+	CacheSize int
+	CacheTTL  time.Duration
+
+	// event attachments are stored below BlobDir
+	BlobDir           string
+	AttachmentMaxSize int64 // bytes
+
+	// sessions checked on every authenticated request, 0 entries turns the cache off
+	SessionCacheSize int
+	SessionCacheTTL  time.Duration
+
+	// PEM files: the key new tokens are signed with and the keys of tokens still accepted.
+	// Without a signing key a throwaway one is generated at startup.
+	JWTSigningKey   string
+	JWTRetiringKeys []string
+	JWTIssuer       string
+	JWTAudience     string
+	JWTLeeway       time.Duration
+
+	// single sign-on, off without an issuer
+	OIDCIssuer       string
+	OIDCClientID     string
+	OIDCClientSecret string
+	OIDCRedirectURL  string
+	// skip our TOTP check after SSO logins, for providers that enforce their own second factor
+	OIDCTrustMFA bool
+
+	// new passwords are hashed with these, older hashes are upgraded on login
+	PasswordHash      string // argon2id or bcrypt
+	BcryptCost        int
+	Argon2Memory      int // KiB
+	Argon2Iterations  int
+	Argon2Parallelism int
+	PasswordMinLength int
+
+	// the name authenticator apps show for the account
+	TOTPIssuer string
+}
+
+func Load() Config {
+	return Config{
+		Port:             getEnv("PORT", "8080"),
+		GRPCPort:         getEnv("GRPC_PORT", "9090"),
+		ReminderOffsets:  getDurations("REMINDER_OFFSETS", []time.Duration{24 * time.Hour, time.Hour}),
+		ReminderInterval: getDuration("REMINDER_INTERVAL", time.Minute),
+		Notifier:         getEnv("NOTIFIER", "log"),
+		NotifyFile:       getEnv("NOTIFY_FILE", "notifications.log"),
+		NotifyWebhookURL: getEnv("NOTIFY_WEBHOOK_URL", ""),
+
+		JobConcurrency:       getInt("JOB_CONCURRENCY", 4),
+		JobVisibilityTimeout: getDuration("JOB_VISIBILITY_TIMEOUT", 30*time.Second),
+		JobPollInterval:      getDuration("JOB_POLL_INTERVAL", time.Second),
+
+		CacheSize: getInt("CACHE_SIZE", 1000),
+		CacheTTL:  getDuration("CACHE_TTL", 30*time.Second),
+
+		BlobDir:           getEnv("BLOB_DIR", "uploads"),
+		AttachmentMaxSize: int64(getInt("ATTACHMENT_MAX_SIZE", 10<<20)),
+
+		SessionCacheSize: getInt("SESSION_CACHE_SIZE", 10000),
+		SessionCacheTTL:  getDuration("SESSION_CACHE_TTL", time.Minute),
+
+		JWTSigningKey:   getEnv("JWT_SIGNING_KEY", ""),
+		JWTRetiringKeys: getList("JWT_RETIRING_KEYS"),
+		JWTIssuer:       getEnv("JWT_ISSUER", "events-booking"),
+		JWTAudience:     getEnv("JWT_AUDIENCE", "events-booking"),
+		JWTLeeway:       getOptionalDuration("JWT_LEEWAY", 30*time.Second),
+
+		OIDCIssuer:       getEnv("OIDC_ISSUER", ""),
+		OIDCClientID:     getEnv("OIDC_CLIENT_ID", "events-booking"),
+		OIDCClientSecret: getEnv("OIDC_CLIENT_SECRET", ""),
+		OIDCRedirectURL:  getEnv("OIDC_REDIRECT_URL", "http://localhost:"+getEnv("PORT", "8080")+"/auth/oidc/callback"),
+		OIDCTrustMFA:     getBool("OIDC_TRUST_MFA", false),
+
+		PasswordHash:      getEnv("PASSWORD_HASH", "argon2id"),
+		BcryptCost:        getInt("BCRYPT_COST", 12),
+		Argon2Memory:      getInt("ARGON2_MEMORY", 19*1024),
+		Argon2Iterations:  getInt("ARGON2_ITERATIONS", 2),
+		Argon2Parallelism: getInt("ARGON2_PARALLELISM", 1),
+		PasswordMinLength: getInt("PASSWORD_MIN_LENGTH", 10),
+
+		TOTPIssuer: getEnv("TOTP_ISSUER", "Events Booking"),
+	}
+}
+
+func getEnv(key string, fallback string) string {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback
+	}
+	return value
+}
+
+func getInt(key string, fallback int) int {
+	value, err := strconv.Atoi(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+func getBool(key string, fallback bool) bool {
+	value, err := strconv.ParseBool(getEnv(key, ""))
+	if err != nil {
+		return fallback
+	}
+	return value
+}
+
+// getDuration falls back to the default for values that don't parse or aren't positive,
+// they would make tickers panic.
+func getDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
+
+// getOptionalDuration is getDuration for settings where 0 turns something off.
+func getOptionalDuration(key string, fallback time.Duration) time.Duration {
+	value, err := time.ParseDuration(getEnv(key, ""))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// getDurations reads a comma separated list, an invalid entry falls back to the defaults.
+func getDurations(key string, fallback []time.Duration) []time.Duration {
+	value := getEnv(key, "")
+	if value == "" {
+		return fallback
+	}
+
+	var durations []time.Duration
+	for _, part := range strings.Split(value, ",") {
+		d, err := time.ParseDuration(strings.TrimSpace(part))
+		if err != nil || d <= 0 {
+			return fallback
+		}
+		durations = append(durations, d)
+	}
+
+	return durations
+}
+
+// getList reads a comma separated list, skipping empty entries.
+func getList(key string) []string {
+	var list []string
+	for _, part := range strings.Split(getEnv(key, ""), ",") {
+		part = strings.TrimSpace(part)
+		if part != "" {
+			list = append(list, part)
+		}
+	}
+	return list
+}
@@ -25,6 +25,74 @@ func createTables() {
 	createUsersTable()
 	createEventsTable()
 	createRegistrationTable()
+	createEventHostsTable()
+	createTaxonomyTables()
+	createSentRemindersTable()
+	createJobsTable()
+	createIdempotencyKeysTable()
+	createOrganizationsTables()
+	createAPIKeysTable()
+	createSSOTables()
+	createMFATables()
+	createSessionsTable()
+	createAttachmentsTable()
+	createRegistrationEventsTable()
+	createInviteTables()
+	createCommentsTable()
+	createFeedbackTable()
+	createNotificationsTable()
+
+	// columns added after the first release, older DB files need them too
+	addColumn("events", "version", "INTEGER NOT NULL DEFAULT 1")
+	addColumn("events", "category_id", "INTEGER REFERENCES categories(id)")
+	addColumn("events", "org_id", "INTEGER REFERENCES organizations(id)")
+	addColumn("users", "is_admin", "INTEGER NOT NULL DEFAULT 0")
+	// SQLite can't add a column defaulting to CURRENT_TIMESTAMP, older rows stay NULL
+	addColumn("registrations", "created_at", "DATETIME")
+	addColumn("registrations", "status", "TEXT NOT NULL DEFAULT 'confirmed'")
+	addColumn("users", "display_name", "TEXT NOT NULL DEFAULT ''")
+	addColumn("users", "timezone", "TEXT NOT NULL DEFAULT 'UTC'")
+	addColumn("users", "notify_confirmations", "INTEGER NOT NULL DEFAULT 1")
+	addColumn("users", "notify_reminders", "INTEGER NOT NULL DEFAULT 1")
+	addColumn("users", "deleted_at", "DATETIME")
+	addColumn("events", "latitude", "REAL")
+	addColumn("events", "longitude", "REAL")
+	addColumn("events", "requires_approval", "INTEGER NOT NULL DEFAULT 0")
+	addColumn("events", "visibility", "TEXT NOT NULL DEFAULT 'public'")
+	addColumn("events", "access_code_hash", "TEXT")
+	addColumn("events", "ends_at", "DATETIME")
+	addColumn("users", "notify_event_updates", "INTEGER NOT NULL DEFAULT 1")
+	addColumn("users", "notify_event_reschedules", "INTEGER NOT NULL DEFAULT 1")
+	addColumn("users", "notify_event_cancellations", "INTEGER NOT NULL DEFAULT 1")
+
+	createRegistrationUniqueIndex()
+	createEventLocationIndex()
+}
+
+// CREATE TABLE IF NOT EXISTS leaves an existing table as it is,
+// so new columns are added separately if the table does not have them yet.
+func addColumn(table, column, definition string) {
+	rows, err := DB.Query("SELECT name FROM pragma_table_info(?)", table)
+	if err != nil {
+		panic("Could not read columns of " + table + ": " + err.Error())
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var name string
+		err := rows.Scan(&name)
+		if err != nil {
+			panic("Could not read columns of " + table + ": " + err.Error())
+		}
+		if name == column {
+			return
+		}
+	}
+
+	_, err = DB.Exec("ALTER TABLE " + table + " ADD COLUMN " + column + " " + definition)
+	if err != nil {
+		panic("Could not add column " + column + " to " + table + ": " + err.Error())
+	}
 }
 
 func createUsersTable() {
@@ -32,7 +100,8 @@ func createUsersTable() {
 		CREATE TABLE IF NOT EXISTS users (
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
 			email TEXT NOT NULL UNIQUE,
-			password TEXT NOT NULL
+			password TEXT NOT NULL,
+			is_admin INTEGER NOT NULL DEFAULT 0
 		)`
 
 	_, err := DB.Exec(createUsersTableQuery)
@@ -52,7 +121,12 @@ func createEventsTable() {
 		date DATETIME NOT NULL,
 		location TEXT not NULL,
 		user_id INTEGER NOT NULL,
-		FOREIGN KEY(user_id) REFERENCES users(id)
+		version INTEGER NOT NULL DEFAULT 1,
+		category_id INTEGER,
+		org_id INTEGER,
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		FOREIGN KEY(category_id) REFERENCES categories(id),
+		FOREIGN KEY(org_id) REFERENCES organizations(id)
 	)`
 
 	_, err := DB.Exec(createEventsTable)
@@ -68,6 +142,8 @@ func createRegistrationTable() {
 		id INTEGER PRIMARY KEY AUTOINCREMENT,
 		user_id INTEGER NOT NULL,
 		event_id INTEGER NOT NULL,
+		created_at DATETIME,
+		status TEXT NOT NULL DEFAULT 'confirmed',
 		FOREIGN KEY(user_id) REFERENCES users(id),
 		FOREIGN KEY(event_id) REFERENCES events(id)
 	)`
@@ -78,3 +154,548 @@ func createRegistrationTable() {
 		panic("Could not create registration tables: " + err.Error())
 	}
 }
+
+// older DB files may hold duplicate sign-ups, only the first one is kept
+// before a user can be registered once per event
+func createRegistrationUniqueIndex() {
+	removeDuplicates := `
+	DELETE FROM registrations WHERE id NOT IN (
+		SELECT MIN(id) FROM registrations GROUP BY user_id, event_id
+	)`
+
+	_, err := DB.Exec(removeDuplicates)
+
+	if err != nil {
+		panic("Could not remove duplicate registrations: " + err.Error())
+	}
+
+	_, err = DB.Exec(`CREATE UNIQUE INDEX IF NOT EXISTS registrations_user_event ON registrations(user_id, event_id)`)
+
+	if err != nil {
+		panic("Could not create registrations index: " + err.Error())
+	}
+}
+
+// the nearby search narrows down by a latitude range before computing distances
+func createEventLocationIndex() {
+	_, err := DB.Exec(`CREATE INDEX IF NOT EXISTS events_location ON events(latitude, longitude) WHERE latitude IS NOT NULL`)
+
+	if err != nil {
+		panic("Could not create events location index: " + err.Error())
+	}
+}
+
+func createEventHostsTable() {
+	createEventHostsTable := `
+	CREATE TABLE IF NOT EXISTS event_hosts (
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		status TEXT NOT NULL,
+		invited_by INTEGER,
+		created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY(event_id, user_id),
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createEventHostsTable)
+
+	if err != nil {
+		panic("Could not create event hosts tables: " + err.Error())
+	}
+
+	// events created before hosts existed get their creator as the owner
+	backfillOwners := `
+	INSERT OR IGNORE INTO event_hosts (event_id, user_id, role, status)
+	SELECT id, user_id, 'owner', 'accepted' FROM events`
+
+	_, err = DB.Exec(backfillOwners)
+
+	if err != nil {
+		panic("Could not backfill event owners: " + err.Error())
+	}
+}
+
+func createTaxonomyTables() {
+	createCategoriesTable := `
+	CREATE TABLE IF NOT EXISTS categories (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		slug TEXT NOT NULL UNIQUE
+	)`
+
+	_, err := DB.Exec(createCategoriesTable)
+
+	if err != nil {
+		panic("Could not create categories tables: " + err.Error())
+	}
+
+	createTagsTable := `
+	CREATE TABLE IF NOT EXISTS tags (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE
+	)`
+
+	_, err = DB.Exec(createTagsTable)
+
+	if err != nil {
+		panic("Could not create tags tables: " + err.Error())
+	}
+
+	createEventTagsTable := `
+	CREATE TABLE IF NOT EXISTS event_tags (
+		event_id INTEGER NOT NULL,
+		tag_id INTEGER NOT NULL,
+		PRIMARY KEY(event_id, tag_id),
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(tag_id) REFERENCES tags(id)
+	)`
+
+	_, err = DB.Exec(createEventTagsTable)
+
+	if err != nil {
+		panic("Could not create event tags tables: " + err.Error())
+	}
+}
+
+func createSentRemindersTable() {
+	createSentRemindersTable := `
+	CREATE TABLE IF NOT EXISTS sent_reminders (
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		offset_seconds INTEGER NOT NULL,
+		sent_at DATETIME NOT NULL,
+		PRIMARY KEY(event_id, user_id, offset_seconds),
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createSentRemindersTable)
+
+	if err != nil {
+		panic("Could not create sent reminders tables: " + err.Error())
+	}
+}
+
+func createJobsTable() {
+	// run_at, locked_until, created_at and updated_at are unix milliseconds
+	createJobsTable := `
+	CREATE TABLE IF NOT EXISTS jobs (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		kind TEXT NOT NULL,
+		payload TEXT NOT NULL,
+		status TEXT NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL,
+		run_at INTEGER NOT NULL,
+		unique_key TEXT,
+		lease TEXT,
+		locked_until INTEGER,
+		last_error TEXT,
+		created_at INTEGER NOT NULL,
+		updated_at INTEGER NOT NULL
+	)`
+
+	_, err := DB.Exec(createJobsTable)
+
+	if err != nil {
+		panic("Could not create jobs tables: " + err.Error())
+	}
+
+	// only one pending job per unique key, finished jobs don't count
+	createJobsIndexes := `
+	CREATE UNIQUE INDEX IF NOT EXISTS jobs_unique_key ON jobs(unique_key)
+		WHERE unique_key IS NOT NULL AND status IN ('queued', 'running');
+	CREATE INDEX IF NOT EXISTS jobs_due ON jobs(status, run_at)`
+
+	_, err = DB.Exec(createJobsIndexes)
+
+	if err != nil {
+		panic("Could not create jobs indexes: " + err.Error())
+	}
+}
+
+func createIdempotencyKeysTable() {
+	// locked_until and expires_at are unix milliseconds
+	createIdempotencyKeysTable := `
+	CREATE TABLE IF NOT EXISTS idempotency_keys (
+		user_id INTEGER NOT NULL,
+		key TEXT NOT NULL,
+		request_hash TEXT NOT NULL,
+		status_code INTEGER,
+		content_type TEXT,
+		body BLOB,
+		locked_until INTEGER,
+		expires_at INTEGER NOT NULL,
+		PRIMARY KEY(user_id, key),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createIdempotencyKeysTable)
+
+	if err != nil {
+		panic("Could not create idempotency keys tables: " + err.Error())
+	}
+}
+
+func createOrganizationsTables() {
+	createOrganizationsTable := `
+	CREATE TABLE IF NOT EXISTS organizations (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		slug TEXT NOT NULL UNIQUE,
+		visibility TEXT NOT NULL DEFAULT 'public',
+		created_at DATETIME NOT NULL
+	)`
+
+	_, err := DB.Exec(createOrganizationsTable)
+
+	if err != nil {
+		panic("Could not create organizations tables: " + err.Error())
+	}
+
+	createOrgMembersTable := `
+	CREATE TABLE IF NOT EXISTS org_members (
+		org_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY(org_id, user_id),
+		FOREIGN KEY(org_id) REFERENCES organizations(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err = DB.Exec(createOrgMembersTable)
+
+	if err != nil {
+		panic("Could not create org members tables: " + err.Error())
+	}
+}
+
+func createAPIKeysTable() {
+	// only the SHA-256 of the key is stored, prefix finds the row without it
+	createAPIKeysTable := `
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		prefix TEXT NOT NULL UNIQUE,
+		key_hash TEXT NOT NULL,
+		scopes TEXT NOT NULL,
+		expires_at DATETIME,
+		last_used_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createAPIKeysTable)
+
+	if err != nil {
+		panic("Could not create api keys tables: " + err.Error())
+	}
+}
+
+func createSSOTables() {
+	// users signed in through an OIDC provider, by the provider's user id
+	createUserIdentitiesTable := `
+	CREATE TABLE IF NOT EXISTS user_identities (
+		issuer TEXT NOT NULL,
+		subject TEXT NOT NULL,
+		user_id INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		PRIMARY KEY(issuer, subject),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createUserIdentitiesTable)
+
+	if err != nil {
+		panic("Could not create user identities tables: " + err.Error())
+	}
+
+	// logins between the redirect to the provider and the callback, expires_at is unix milliseconds
+	createOIDCLoginsTable := `
+	CREATE TABLE IF NOT EXISTS oidc_logins (
+		state TEXT PRIMARY KEY,
+		nonce TEXT NOT NULL,
+		code_verifier TEXT NOT NULL,
+		expires_at INTEGER NOT NULL
+	)`
+
+	_, err = DB.Exec(createOIDCLoginsTable)
+
+	if err != nil {
+		panic("Could not create oidc logins tables: " + err.Error())
+	}
+}
+
+func createMFATables() {
+	// confirmed_at stays NULL until the first code is entered, last_step is the
+	// time step of the last accepted code, so a code can't be used twice
+	createUserTOTPTable := `
+	CREATE TABLE IF NOT EXISTS user_totp (
+		user_id INTEGER PRIMARY KEY,
+		secret TEXT NOT NULL,
+		confirmed_at DATETIME,
+		last_step INTEGER NOT NULL DEFAULT 0,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createUserTOTPTable)
+
+	if err != nil {
+		panic("Could not create user totp tables: " + err.Error())
+	}
+
+	// only the SHA-256 of each code is stored, like the API keys
+	createRecoveryCodesTable := `
+	CREATE TABLE IF NOT EXISTS mfa_recovery_codes (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		code_hash TEXT NOT NULL,
+		used_at DATETIME,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err = DB.Exec(createRecoveryCodesTable)
+
+	if err != nil {
+		panic("Could not create mfa recovery codes tables: " + err.Error())
+	}
+
+	// logins waiting for the second factor, expires_at is unix milliseconds
+	createMFAChallengesTable := `
+	CREATE TABLE IF NOT EXISTS mfa_challenges (
+		token_hash TEXT PRIMARY KEY,
+		user_id INTEGER NOT NULL,
+		attempts INTEGER NOT NULL DEFAULT 0,
+		expires_at INTEGER NOT NULL,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err = DB.Exec(createMFAChallengesTable)
+
+	if err != nil {
+		panic("Could not create mfa challenges tables: " + err.Error())
+	}
+}
+
+func createSessionsTable() {
+	// one row per login, the JWT carries the id; revoked and expired rows are
+	// cleaned up at the user's next login
+	createSessionsTable := `
+	CREATE TABLE IF NOT EXISTS sessions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		user_agent TEXT NOT NULL,
+		ip TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		last_seen_at DATETIME NOT NULL,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createSessionsTable)
+
+	if err != nil {
+		panic("Could not create sessions tables: " + err.Error())
+	}
+
+	_, err = DB.Exec(`CREATE INDEX IF NOT EXISTS sessions_user ON sessions(user_id)`)
+
+	if err != nil {
+		panic("Could not create sessions index: " + err.Error())
+	}
+}
+
+func createAttachmentsTable() {
+	// the files are in the blob store, thumbnail_key is NULL for anything but images
+	createAttachmentsTable := `
+	CREATE TABLE IF NOT EXISTS event_attachments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		filename TEXT NOT NULL,
+		content_type TEXT NOT NULL,
+		size INTEGER NOT NULL,
+		sha256 TEXT NOT NULL,
+		blob_key TEXT NOT NULL,
+		thumbnail_key TEXT,
+		uploaded_by INTEGER NOT NULL,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(uploaded_by) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createAttachmentsTable)
+
+	if err != nil {
+		panic("Could not create event attachments tables: " + err.Error())
+	}
+
+	_, err = DB.Exec(`CREATE INDEX IF NOT EXISTS event_attachments_event ON event_attachments(event_id)`)
+
+	if err != nil {
+		panic("Could not create event attachments index: " + err.Error())
+	}
+}
+
+func createRegistrationEventsTable() {
+	// one row per status change, from_status is NULL for the sign-up and actor_id
+	// for changes nobody made by hand
+	createRegistrationEventsTable := `
+	CREATE TABLE IF NOT EXISTS registration_events (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		registration_id INTEGER NOT NULL,
+		from_status TEXT,
+		to_status TEXT NOT NULL,
+		actor_id INTEGER,
+		reason TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY(registration_id) REFERENCES registrations(id),
+		FOREIGN KEY(actor_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createRegistrationEventsTable)
+
+	if err != nil {
+		panic("Could not create registration events tables: " + err.Error())
+	}
+
+	_, err = DB.Exec(`CREATE INDEX IF NOT EXISTS registration_events_registration ON registration_events(registration_id)`)
+
+	if err != nil {
+		panic("Could not create registration events index: " + err.Error())
+	}
+}
+
+func createInviteTables() {
+	// only the SHA-256 of the token is stored, it is looked up by that
+	createEventInvitesTable := `
+	CREATE TABLE IF NOT EXISTS event_invites (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_by INTEGER NOT NULL,
+		max_uses INTEGER NOT NULL,
+		uses INTEGER NOT NULL DEFAULT 0,
+		expires_at DATETIME NOT NULL,
+		revoked_at DATETIME,
+		created_at DATETIME NOT NULL,
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(created_by) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createEventInvitesTable)
+
+	if err != nil {
+		panic("Could not create event invites tables: " + err.Error())
+	}
+
+	// who got into a private event, invite_id is NULL for the access code
+	createEventAccessTable := `
+	CREATE TABLE IF NOT EXISTS event_access (
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		invite_id INTEGER,
+		granted_at DATETIME NOT NULL,
+		PRIMARY KEY(event_id, user_id),
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		FOREIGN KEY(invite_id) REFERENCES event_invites(id)
+	)`
+
+	_, err = DB.Exec(createEventAccessTable)
+
+	if err != nil {
+		panic("Could not create event invites tables: " + err.Error())
+	}
+}
+
+func createCommentsTable() {
+	// replies point to the first comment of their thread, body_html is rendered
+	// from the markdown in body on every write
+	createCommentsTable := `
+	CREATE TABLE IF NOT EXISTS event_comments (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		parent_id INTEGER,
+		user_id INTEGER NOT NULL,
+		body TEXT NOT NULL,
+		body_html TEXT NOT NULL,
+		pinned_at DATETIME,
+		hidden_at DATETIME,
+		hidden_by INTEGER,
+		created_at DATETIME NOT NULL,
+		edited_at DATETIME,
+		deleted_at DATETIME,
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(parent_id) REFERENCES event_comments(id),
+		FOREIGN KEY(user_id) REFERENCES users(id),
+		FOREIGN KEY(hidden_by) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createCommentsTable)
+
+	if err != nil {
+		panic("Could not create event comments tables: " + err.Error())
+	}
+
+	_, err = DB.Exec(`CREATE INDEX IF NOT EXISTS event_comments_thread ON event_comments(event_id, parent_id, id)`)
+
+	if err != nil {
+		panic("Could not create event comments index: " + err.Error())
+	}
+}
+
+func createFeedbackTable() {
+	createFeedbackTable := `
+	CREATE TABLE IF NOT EXISTS event_feedback (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		event_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		rating INTEGER NOT NULL CHECK (rating BETWEEN 1 AND 5),
+		review TEXT NOT NULL DEFAULT '',
+		created_at DATETIME NOT NULL,
+		updated_at DATETIME NOT NULL,
+		UNIQUE(event_id, user_id),
+		FOREIGN KEY(event_id) REFERENCES events(id),
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createFeedbackTable)
+
+	if err != nil {
+		panic("Could not create event feedback tables: " + err.Error())
+	}
+}
+
+func createNotificationsTable() {
+	// event_id has no foreign key, the notification of a cancellation outlives the event
+	createNotificationsTable := `
+	CREATE TABLE IF NOT EXISTS notifications (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		kind TEXT NOT NULL,
+		event_id INTEGER NOT NULL,
+		event_name TEXT NOT NULL,
+		message TEXT NOT NULL,
+		created_at DATETIME NOT NULL,
+		read_at DATETIME,
+		FOREIGN KEY(user_id) REFERENCES users(id)
+	)`
+
+	_, err := DB.Exec(createNotificationsTable)
+
+	if err != nil {
+		panic("Could not create notifications tables: " + err.Error())
+	}
+
+	_, err = DB.Exec(`CREATE INDEX IF NOT EXISTS notifications_inbox ON notifications(user_id, read_at, id)`)
+
+	if err != nil {
+		panic("Could not create notifications index: " + err.Error())
+	}
+}
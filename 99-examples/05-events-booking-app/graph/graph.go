@@ -0,0 +1,112 @@
+// Package graph serves the events, users and registrations as one GraphQL schema,
+// so a screen can load everything it shows with a single request. Resolvers call the
+// same models functions as the REST handlers and relations are loaded in batches.
+package graph
+
+import (
+	"context"
+	"events-booking/models"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+// Viewer is who is asking, taken from the JWT and the active organization.
+type Viewer struct {
+	UserID  int64  // 0 for anonymous requests
+	OrgID   *int64 // nil outside any organization
+	OrgRole string // "" if the user is not a member of the organization
+}
+
+type Params struct {
+	Query         string
+	OperationName string
+	Variables     map[string]any
+}
+
+// request is the per-request state handed to the resolvers through the context.
+type request struct {
+	Viewer
+	loaders *loaders
+
+	adminOnce sync.Once
+	admin     bool
+	adminErr  error
+}
+
+type requestKey struct{}
+
+func requestFrom(ctx context.Context) *request {
+	return ctx.Value(requestKey{}).(*request)
+}
+
+// isAdmin is only looked up when a resolver needs it.
+func (r *request) isAdmin() (bool, error) {
+	r.adminOnce.Do(func() {
+		if r.UserID != 0 {
+			r.admin, r.adminErr = models.IsAdmin(r.UserID)
+		}
+	})
+
+	return r.admin, r.adminErr
+}
+
+// Execute runs the query for the viewer. executed is false when the query was
+// rejected before running: it doesn't parse, is invalid or is too expensive.
+func Execute(ctx context.Context, viewer Viewer, params Params) (result *graphql.Result, executed bool) {
+	src := source.NewSource(&source.Source{Body: []byte(params.Query), Name: "GraphQL request"})
+
+	doc, err := parser.Parse(parser.ParseParams{Source: src})
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	validation := graphql.ValidateDocument(&schema, doc, nil)
+	if !validation.IsValid {
+		return &graphql.Result{Errors: validation.Errors}, false
+	}
+
+	err = checkLimits(doc)
+	if err != nil {
+		return &graphql.Result{Errors: gqlerrors.FormatErrors(err)}, false
+	}
+
+	req := &request{Viewer: viewer}
+	req.loaders = newLoaders(req)
+
+	return graphql.Execute(graphql.ExecuteParams{
+		Schema:        schema,
+		AST:           doc,
+		OperationName: params.OperationName,
+		Args:          params.Variables,
+		Context:       context.WithValue(ctx, requestKey{}, req),
+	}), true
+}
+
+// Error codes sent in the "extensions" of an error.
+const (
+	CodeUnauthenticated = "UNAUTHENTICATED"
+	CodeForbidden       = "FORBIDDEN"
+	CodeNotFound        = "NOT_FOUND"
+	CodeBadInput        = "BAD_USER_INPUT"
+	CodeConflict        = "CONFLICT"
+)
+
+// codedError tells clients why a field failed without them parsing the message.
+type codedError struct {
+	message string
+	code    string
+}
+
+func (e codedError) Error() string {
+	return e.message
+}
+
+func (e codedError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+var errNotAuthorized = codedError{"Not Authorized", CodeUnauthenticated}
@@ -0,0 +1,125 @@
+package graph
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+const (
+	// MaxDepth is how deeply selections may be nested, events { organizer { events { ... } } } is 3.
+	MaxDepth = 6
+	// MaxComplexity caps the estimated number of resolved fields. Every field costs 1
+	// and the selections below a list count listFactor times.
+	MaxComplexity = 1000
+	listFactor    = 10
+)
+
+// checkLimits rejects queries that would be too expensive before they run.
+// Introspection (__schema, __type) is not counted, GraphiQL needs it.
+func checkLimits(doc *ast.Document) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		fragment, ok := def.(*ast.FragmentDefinition)
+		if ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		root := schema.QueryType()
+		if op.Operation == ast.OperationTypeMutation {
+			root = schema.MutationType()
+		}
+
+		c := costCounter{fragments: fragments}
+		complexity := c.selections(op.SelectionSet, root, 1)
+
+		if c.depth > MaxDepth {
+			return fmt.Errorf("query is nested %d levels deep, the limit is %d", c.depth, MaxDepth)
+		}
+		if complexity > MaxComplexity {
+			return fmt.Errorf("query complexity is %d, the limit is %d", complexity, MaxComplexity)
+		}
+	}
+
+	return nil
+}
+
+type costCounter struct {
+	fragments map[string]*ast.FragmentDefinition
+	depth     int // deepest level seen
+}
+
+// selections returns the cost of the selection set on parent, found at the given depth.
+// Validation already ran, so every field exists and fragments don't loop.
+func (c *costCounter) selections(set *ast.SelectionSet, parent *graphql.Object, depth int) int {
+	if set == nil {
+		return 0
+	}
+
+	total := 0
+	for _, selection := range set.Selections {
+		switch s := selection.(type) {
+		case *ast.Field:
+			total += c.field(s, parent, depth)
+		case *ast.InlineFragment:
+			total += c.selections(s.SelectionSet, parent, depth)
+		case *ast.FragmentSpread:
+			fragment, ok := c.fragments[s.Name.Value]
+			if ok {
+				total += c.selections(fragment.SelectionSet, parent, depth)
+			}
+		}
+	}
+
+	return total
+}
+
+func (c *costCounter) field(f *ast.Field, parent *graphql.Object, depth int) int {
+	if strings.HasPrefix(f.Name.Value, "__") {
+		return 0
+	}
+
+	c.depth = max(c.depth, depth)
+
+	def, ok := parent.Fields()[f.Name.Value]
+	if !ok {
+		return 1
+	}
+
+	// unwrap [Type!]! down to the named type
+	fieldType := def.Type
+	isList := false
+	for {
+		switch t := fieldType.(type) {
+		case *graphql.NonNull:
+			fieldType = t.OfType
+			continue
+		case *graphql.List:
+			isList = true
+			fieldType = t.OfType
+			continue
+		}
+		break
+	}
+
+	object, ok := fieldType.(*graphql.Object)
+	if !ok {
+		return 1
+	}
+
+	nested := c.selections(f.SelectionSet, object, depth+1)
+	if isList {
+		nested *= listFactor
+	}
+
+	return 1 + nested
+}
@@ -0,0 +1,167 @@
+package graph
+
+import (
+	"events-booking/models"
+	"events-booking/permissions"
+	"slices"
+	"sync"
+)
+
+// loader is a small dataloader. load only queues the ID and returns a thunk;
+// graphql-go resolves every field of one level of the query before it calls
+// the thunks, so the first thunk fetches the IDs of all its siblings at once.
+type loader[V any] struct {
+	fetch   func(ids []int64) (map[int64]V, error)
+	missing interface{} // what IDs without a result resolve to
+
+	mu      sync.Mutex
+	queued  []int64
+	values  map[int64]V
+	fetched map[int64]error // nil once fetched successfully
+}
+
+func newLoader[V any](fetch func(ids []int64) (map[int64]V, error), missing interface{}) *loader[V] {
+	return &loader[V]{
+		fetch:   fetch,
+		missing: missing,
+		values:  map[int64]V{},
+		fetched: map[int64]error{},
+	}
+}
+
+func (l *loader[V]) load(id int64) func() (interface{}, error) {
+	l.mu.Lock()
+	_, done := l.fetched[id]
+	if !done && !slices.Contains(l.queued, id) {
+		l.queued = append(l.queued, id)
+	}
+	l.mu.Unlock()
+
+	return func() (interface{}, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if _, done := l.fetched[id]; !done {
+			l.fetchQueued()
+		}
+
+		err := l.fetched[id]
+		if err != nil {
+			return nil, err
+		}
+
+		value, ok := l.values[id]
+		if !ok {
+			return l.missing, nil
+		}
+
+		return value, nil
+	}
+}
+
+func (l *loader[V]) fetchQueued() {
+	ids := l.queued
+	l.queued = nil
+
+	found, err := l.fetch(ids)
+	for _, id := range ids {
+		l.fetched[id] = err
+		value, ok := found[id]
+		if ok {
+			l.values[id] = value
+		}
+	}
+}
+
+// loaders live for one request, they cache what the request may see.
+type loaders struct {
+	users         *loader[models.User]
+	events        *loader[models.Event]
+	eventsByOwner *loader[[]models.Event]
+	categories    *loader[models.Category]
+
+	// only has the events whose attendees the viewer may see, the others resolve to null
+	eventRegistrations *loader[[]models.Registration]
+	userRegistrations  *loader[[]models.Registration]
+}
+
+func newLoaders(req *request) *loaders {
+	return &loaders{
+		users: newLoader(models.GetUsersByIDs, nil),
+		events: newLoader(func(ids []int64) (map[int64]models.Event, error) {
+			found, err := models.GetOrgEventsByIDs(req.OrgID, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			// private events the viewer may not see resolve to null
+			for id, e := range found {
+				allowed, err := models.CanViewEvent(req.UserID, &e)
+				if err != nil {
+					return nil, err
+				}
+				if !allowed {
+					delete(found, id)
+				}
+			}
+			return found, nil
+		}, nil),
+		eventsByOwner: newLoader(func(ids []int64) (map[int64][]models.Event, error) {
+			owned, err := models.GetOrgEventsByOwners(req.OrgID, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			// users see all their own events, other people only the public ones
+			for owner, list := range owned {
+				if owner == req.UserID {
+					continue
+				}
+				owned[owner], err = models.FilterVisibleEvents(req.UserID, list)
+				if err != nil {
+					return nil, err
+				}
+			}
+			return owned, nil
+		}, []models.Event{}),
+		categories: newLoader(func(ids []int64) (map[int64]models.Category, error) {
+			// there are few categories, load them all
+			list, err := models.GetAllCategories()
+			if err != nil {
+				return nil, err
+			}
+
+			categories := map[int64]models.Category{}
+			for _, c := range list {
+				categories[c.ID] = c
+			}
+			return categories, nil
+		}, nil),
+		eventRegistrations: newLoader(func(ids []int64) (map[int64][]models.Registration, error) {
+			roles, err := models.GetHostRoles(req.UserID, ids)
+			if err != nil {
+				return nil, err
+			}
+
+			var allowed []int64
+			for _, id := range ids {
+				if permissions.RoleAllows(roles[id], permissions.ViewAttendees) {
+					allowed = append(allowed, id)
+				}
+			}
+
+			regs, err := models.GetRegistrationsByEvents(allowed)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, id := range allowed {
+				if regs[id] == nil {
+					regs[id] = []models.Registration{}
+				}
+			}
+			return regs, nil
+		}, nil),
+		userRegistrations: newLoader(models.GetRegistrationsByUsers, []models.Registration{}),
+	}
+}
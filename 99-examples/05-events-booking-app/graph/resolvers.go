@@ -0,0 +1,387 @@
+package graph
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/models"
+	"events-booking/permissions"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/graphql-go/graphql"
+)
+
+// ID arguments arrive as strings (or ints in the query text)
+func idArg(args map[string]interface{}, name string) (int64, error) {
+	id, err := strconv.ParseInt(fmt.Sprint(args[name]), 10, 64)
+	if err != nil {
+		return 0, codedError{"Could not parse the " + name, CodeBadInput}
+	}
+
+	return id, nil
+}
+
+func stringsArg(args map[string]interface{}, name string) []string {
+	list, _ := args[name].([]interface{})
+
+	values := make([]string, 0, len(list))
+	for _, v := range list {
+		values = append(values, v.(string))
+	}
+
+	return values
+}
+
+func resolveEvents(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+
+	category, _ := p.Args["category"].(string)
+	filter := models.EventFilter{
+		OrgID:    req.OrgID,
+		Category: category,
+		Tags:     stringsArg(p.Args, "tags"),
+	}
+
+	return models.GetEvents(filter)
+}
+
+func resolveEvent(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return requestFrom(p.Context).loaders.events.load(id), nil
+}
+
+func resolveMe(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, nil
+	}
+
+	return req.loaders.users.load(req.UserID), nil
+}
+
+func resolveUser(p graphql.ResolveParams) (interface{}, error) {
+	id, err := idArg(p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	return requestFrom(p.Context).loaders.users.load(id), nil
+}
+
+func resolveUsers(p graphql.ResolveParams) (interface{}, error) {
+	// like GET /users, listing everyone's email needs a login
+	if requestFrom(p.Context).UserID == 0 {
+		return nil, errNotAuthorized
+	}
+	return models.GetAllUsers()
+}
+
+func resolveRegistrations(p graphql.ResolveParams) (interface{}, error) {
+	isAdmin, err := requestFrom(p.Context).isAdmin()
+	if err != nil {
+		return nil, err
+	}
+	if !isAdmin {
+		return nil, codedError{"Only admins can list every registration.", CodeForbidden}
+	}
+
+	return models.GetAllRegistrations()
+}
+
+func resolveCategories(p graphql.ResolveParams) (interface{}, error) {
+	return models.GetAllCategories()
+}
+
+func resolveEventCategory(p graphql.ResolveParams) (interface{}, error) {
+	e := p.Source.(models.Event)
+	if e.CategoryID == nil {
+		return nil, nil
+	}
+
+	return requestFrom(p.Context).loaders.categories.load(*e.CategoryID), nil
+}
+
+func resolveEventOrganizer(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).loaders.users.load(p.Source.(models.Event).UserID), nil
+}
+
+func resolveEventRegistrations(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, nil
+	}
+
+	return req.loaders.eventRegistrations.load(p.Source.(models.Event).ID), nil
+}
+
+// resolveUserEmail keeps anonymous callers from collecting emails one user at a
+// time, the users list needs a login for the same reason.
+func resolveUserEmail(p graphql.ResolveParams) (interface{}, error) {
+	if requestFrom(p.Context).UserID == 0 {
+		return nil, errNotAuthorized
+	}
+	return p.Source.(models.User).Email, nil
+}
+
+func resolveUserEvents(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).loaders.eventsByOwner.load(p.Source.(models.User).Id), nil
+}
+
+func resolveUserRegistrations(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	u := p.Source.(models.User)
+
+	if req.UserID == 0 {
+		return nil, nil
+	}
+	if u.Id != req.UserID {
+		isAdmin, err := req.isAdmin()
+		if err != nil || !isAdmin {
+			return nil, err
+		}
+	}
+
+	return req.loaders.userRegistrations.load(u.Id), nil
+}
+
+func resolveRegistrationUser(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).loaders.users.load(p.Source.(models.Registration).UserID), nil
+}
+
+func resolveRegistrationEvent(p graphql.ResolveParams) (interface{}, error) {
+	return requestFrom(p.Context).loaders.events.load(p.Source.(models.Registration).EventID), nil
+}
+
+// eventFromInput builds the event from an EventInput and checks it with the
+// same binding rules as the REST handlers.
+func eventFromInput(args map[string]interface{}) (models.Event, error) {
+	input := args["input"].(map[string]interface{})
+
+	var e models.Event
+	e.Name, _ = input["name"].(string)
+	e.Description, _ = input["description"].(string)
+	e.DateTime, _ = input["date"].(time.Time)
+	e.Location, _ = input["location"].(string)
+
+	if input["categoryId"] != nil {
+		categoryID, err := idArg(input, "categoryId")
+		if err != nil {
+			return e, err
+		}
+		e.CategoryID = &categoryID
+	}
+
+	if input["tags"] != nil {
+		e.Tags = stringsArg(input, "tags")
+	}
+
+	err := binding.Validator.ValidateStruct(&e)
+	if err != nil {
+		return e, codedError{err.Error(), CodeBadInput}
+	}
+
+	return e, nil
+}
+
+// findEvent loads the event from the id argument inside the active organization.
+func findEvent(req *request, args map[string]interface{}, name string) (*models.Event, error) {
+	id, err := idArg(args, name)
+	if err != nil {
+		return nil, err
+	}
+
+	event, err := models.GetOrgEventByID(req.OrgID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, codedError{"Event not found", CodeNotFound}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// like REST, private events the caller may not see don't exist
+	allowed, err := models.CanViewEvent(req.UserID, event)
+	if err != nil {
+		return nil, err
+	}
+	if !allowed {
+		return nil, codedError{"Event not found", CodeNotFound}
+	}
+
+	return event, nil
+}
+
+// authorize is the GraphQL counterpart of the REST authorize helper.
+func authorize(req *request, event *models.Event, action permissions.Action, message string) error {
+	allowed, err := permissions.Can(req.UserID, event, action)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return codedError{message, CodeForbidden}
+	}
+
+	return nil
+}
+
+// eventWriteError maps the errors of Save, Update and Delete.
+func eventWriteError(err error) error {
+	if errors.Is(err, models.ErrUnknownCategory) || errors.Is(err, models.ErrInvalidCoordinates) || errors.Is(err, models.ErrInvalidEndTime) {
+		return codedError{err.Error(), CodeBadInput}
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		return codedError{"The event was modified by someone else. Fetch it again and retry.", CodeConflict}
+	}
+
+	return err
+}
+
+func createEvent(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, errNotAuthorized
+	}
+
+	// only members can create events in an organization
+	if req.OrgID != nil && req.OrgRole == "" {
+		return nil, codedError{"Only members of the organization can create events in it.", CodeForbidden}
+	}
+
+	e, err := eventFromInput(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	e.UserID = req.UserID
+	e.OrgID = req.OrgID
+
+	err = e.Save()
+	if err != nil {
+		return nil, eventWriteError(err)
+	}
+
+	return e, nil
+}
+
+func updateEvent(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, errNotAuthorized
+	}
+
+	event, err := findEvent(req, p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorize(req, event, permissions.UpdateEvent, "Only event hosts can update the info.")
+	if err != nil {
+		return nil, err
+	}
+
+	updatedEvent, err := eventFromInput(p.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedEvent.ID = event.ID
+	updatedEvent.UserID = event.UserID
+	updatedEvent.Version = int64(p.Args["version"].(int))
+	updatedEvent.OrgID = event.OrgID
+	// the coordinates, end time and approval mode can only be set through REST, they stay as they are
+	updatedEvent.RequiresApproval = event.RequiresApproval
+	updatedEvent.Latitude, updatedEvent.Longitude = event.Latitude, event.Longitude
+	if event.EndsAt != nil {
+		// a new date moves the end along, the event keeps its length
+		endsAt := updatedEvent.DateTime.Add(event.EndsAt.Sub(event.DateTime))
+		updatedEvent.EndsAt = &endsAt
+	}
+
+	err = updatedEvent.Update()
+	if err != nil {
+		return nil, eventWriteError(err)
+	}
+
+	return updatedEvent, nil
+}
+
+func deleteEvent(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, errNotAuthorized
+	}
+
+	event, err := findEvent(req, p.Args, "id")
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorize(req, event, permissions.DeleteEvent, "Only event owners can delete the info.")
+	if err != nil {
+		return nil, err
+	}
+
+	event.Version = int64(p.Args["version"].(int))
+
+	err = event.Delete()
+	if err != nil {
+		return nil, eventWriteError(err)
+	}
+
+	return *event, nil
+}
+
+func registerForEvent(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, errNotAuthorized
+	}
+
+	event, err := findEvent(req, p.Args, "eventId")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = event.Register(req.UserID)
+	if errors.Is(err, models.ErrAlreadyRegistered) {
+		return nil, codedError{"You are already registered for this event.", CodeConflict}
+	}
+	if errors.Is(err, models.ErrRegistrationRemoved) {
+		return nil, codedError{"The organizer removed you from this event, you can't sign up again.", CodeForbidden}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return *event, nil
+}
+
+func cancelRegistration(p graphql.ResolveParams) (interface{}, error) {
+	req := requestFrom(p.Context)
+	if req.UserID == 0 {
+		return nil, errNotAuthorized
+	}
+
+	event, err := findEvent(req, p.Args, "eventId")
+	if err != nil {
+		return nil, err
+	}
+
+	err = event.CancelRegistration(req.UserID, req.UserID, "")
+	if errors.Is(err, models.ErrRegistrationNotFound) {
+		return nil, codedError{"You are not registered for this event.", CodeNotFound}
+	}
+	if errors.Is(err, models.ErrInvalidTransition) {
+		return nil, codedError{err.Error(), CodeConflict}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return *event, nil
+}
@@ -0,0 +1,170 @@
+package graph
+
+import (
+	"github.com/graphql-go/graphql"
+)
+
+var schema graphql.Schema
+
+var categoryType = graphql.NewObject(graphql.ObjectConfig{
+	Name: "Category",
+	Fields: graphql.Fields{
+		"id":   &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+		"name": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		"slug": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+	},
+})
+
+// the object types refer to each other, so their fields are added in init
+var (
+	eventType        = graphql.NewObject(graphql.ObjectConfig{Name: "Event", Fields: graphql.Fields{}})
+	userType         = graphql.NewObject(graphql.ObjectConfig{Name: "User", Fields: graphql.Fields{}})
+	registrationType = graphql.NewObject(graphql.ObjectConfig{Name: "Registration", Fields: graphql.Fields{}})
+)
+
+var eventInputType = graphql.NewInputObject(graphql.InputObjectConfig{
+	Name: "EventInput",
+	Fields: graphql.InputObjectConfigFieldMap{
+		"name":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"description": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"date":        &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.DateTime)},
+		"location":    &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		"categoryId":  &graphql.InputObjectFieldConfig{Type: graphql.ID},
+		"tags": &graphql.InputObjectFieldConfig{
+			Type:        graphql.NewList(graphql.NewNonNull(graphql.String)),
+			Description: "Replaces the tags, leaving it out keeps the current ones on update.",
+		},
+	},
+})
+
+func init() {
+	eventType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	eventType.AddFieldConfig("name", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	eventType.AddFieldConfig("description", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	eventType.AddFieldConfig("date", &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)})
+	eventType.AddFieldConfig("location", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	eventType.AddFieldConfig("version", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.Int),
+		Description: "Send it back with updateEvent and deleteEvent, like the REST If-Match header.",
+	})
+	eventType.AddFieldConfig("tags", &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))})
+	eventType.AddFieldConfig("category", &graphql.Field{Type: categoryType, Resolve: resolveEventCategory})
+	eventType.AddFieldConfig("organizer", &graphql.Field{Type: userType, Resolve: resolveEventOrganizer})
+	eventType.AddFieldConfig("registrations", &graphql.Field{
+		Type:        graphql.NewList(graphql.NewNonNull(registrationType)),
+		Description: "Only the event's hosts see the registrations, null for everyone else.",
+		Resolve:     resolveEventRegistrations,
+	})
+
+	userType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	userType.AddFieldConfig("email", &graphql.Field{
+		Type:        graphql.String,
+		Description: "Needs a login, null with an UNAUTHENTICATED error otherwise.",
+		Resolve:     resolveUserEmail,
+	})
+	userType.AddFieldConfig("events", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+		Description: "The events the user owns in the active organization.",
+		Resolve:     resolveUserEvents,
+	})
+	userType.AddFieldConfig("registrations", &graphql.Field{
+		Type:        graphql.NewList(graphql.NewNonNull(registrationType)),
+		Description: "Only visible to the user themselves and to admins, null for everyone else.",
+		Resolve:     resolveUserRegistrations,
+	})
+
+	registrationType.AddFieldConfig("id", &graphql.Field{Type: graphql.NewNonNull(graphql.ID)})
+	registrationType.AddFieldConfig("status", &graphql.Field{Type: graphql.NewNonNull(graphql.String)})
+	registrationType.AddFieldConfig("createdAt", &graphql.Field{Type: graphql.DateTime})
+	registrationType.AddFieldConfig("user", &graphql.Field{Type: userType, Resolve: resolveRegistrationUser})
+	registrationType.AddFieldConfig("event", &graphql.Field{
+		Type:        eventType,
+		Description: "Null when the event is in another organization.",
+		Resolve:     resolveRegistrationEvent,
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"events": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(eventType))),
+				Args: graphql.FieldConfigArgument{
+					"category": &graphql.ArgumentConfig{Type: graphql.String, Description: "Category slug"},
+					"tags":     &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String)), Description: "Events having all of these tags"},
+				},
+				Resolve: resolveEvents,
+			},
+			"event": &graphql.Field{
+				Type:    eventType,
+				Args:    graphql.FieldConfigArgument{"id": idArgument},
+				Resolve: resolveEvent,
+			},
+			"me": &graphql.Field{
+				Type:        userType,
+				Description: "The logged in user, null for anonymous requests.",
+				Resolve:     resolveMe,
+			},
+			"user": &graphql.Field{
+				Type:    userType,
+				Args:    graphql.FieldConfigArgument{"id": idArgument},
+				Resolve: resolveUser,
+			},
+			"users": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(userType))),
+				Resolve: resolveUsers,
+			},
+			"registrations": &graphql.Field{
+				Type:        graphql.NewList(graphql.NewNonNull(registrationType)),
+				Description: "Every registration of every user, admins only.",
+				Resolve:     resolveRegistrations,
+			},
+			"categories": &graphql.Field{
+				Type:    graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(categoryType))),
+				Resolve: resolveCategories,
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createEvent": &graphql.Field{
+				Type:    graphql.NewNonNull(eventType),
+				Args:    graphql.FieldConfigArgument{"input": inputArgument},
+				Resolve: createEvent,
+			},
+			"updateEvent": &graphql.Field{
+				Type:    graphql.NewNonNull(eventType),
+				Args:    graphql.FieldConfigArgument{"id": idArgument, "version": versionArgument, "input": inputArgument},
+				Resolve: updateEvent,
+			},
+			"deleteEvent": &graphql.Field{
+				Type:    graphql.NewNonNull(eventType),
+				Args:    graphql.FieldConfigArgument{"id": idArgument, "version": versionArgument},
+				Resolve: deleteEvent,
+			},
+			"registerForEvent": &graphql.Field{
+				Type:    graphql.NewNonNull(eventType),
+				Args:    graphql.FieldConfigArgument{"eventId": idArgument},
+				Resolve: registerForEvent,
+			},
+			"cancelRegistration": &graphql.Field{
+				Type:    graphql.NewNonNull(eventType),
+				Args:    graphql.FieldConfigArgument{"eventId": idArgument},
+				Resolve: cancelRegistration,
+			},
+		},
+	})
+
+	var err error
+	schema, err = graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+	if err != nil {
+		panic("Could not build the GraphQL schema: " + err.Error())
+	}
+}
+
+var (
+	idArgument      = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}
+	versionArgument = &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Int), Description: "The version the change is based on"}
+	inputArgument   = &graphql.ArgumentConfig{Type: graphql.NewNonNull(eventInputType)}
+)
@@ -0,0 +1,75 @@
+package grpcapi
+
+import (
+	"events-booking/models"
+	"events-booking/pb"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func eventToPB(e models.Event) *pb.Event {
+	return &pb.Event{
+		Id:          e.ID,
+		Name:        e.Name,
+		Description: e.Description,
+		Date:        timestamppb.New(e.DateTime),
+		Location:    e.Location,
+		UserId:      e.UserID,
+		Version:     e.Version,
+		CategoryId:  e.CategoryID,
+		Tags:        e.Tags,
+		OrgId:       e.OrgID,
+	}
+}
+
+// eventFromPB copies the input fields, IDs and version are set by the caller.
+func eventFromPB(in *pb.EventInput) models.Event {
+	var e models.Event
+	if in == nil {
+		return e
+	}
+
+	e.Name = in.Name
+	e.Description = in.Description
+	if in.Date != nil {
+		e.DateTime = in.Date.AsTime()
+	}
+	e.Location = in.Location
+	e.CategoryID = in.CategoryId
+	e.Tags = in.Tags
+
+	return e
+}
+
+func userToPB(u models.User) *pb.User {
+	return &pb.User{Id: u.Id, Email: u.Email}
+}
+
+func registrationToPB(r models.Registration) *pb.Registration {
+	reg := &pb.Registration{
+		Id:      r.ID,
+		UserId:  r.UserID,
+		EventId: r.EventID,
+		Status:  r.Status,
+	}
+	if r.CreatedAt != nil {
+		reg.CreatedAt = timestamppb.New(*r.CreatedAt)
+	}
+
+	return reg
+}
+
+func registrationsToPB(regs []models.Registration) *pb.ListRegistrationsResponse {
+	list := make([]*pb.Registration, len(regs))
+	for i, r := range regs {
+		list[i] = registrationToPB(r)
+	}
+
+	return &pb.ListRegistrationsResponse{Registrations: list}
+}
+
+var changeKinds = map[string]pb.EventChange_Kind{
+	models.EventCreated: pb.EventChange_KIND_CREATED,
+	models.EventUpdated: pb.EventChange_KIND_UPDATED,
+	models.EventDeleted: pb.EventChange_KIND_DELETED,
+}
@@ -0,0 +1,235 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"events-booking/models"
+	"events-booking/pb"
+	"events-booking/permissions"
+
+	"github.com/gin-gonic/gin/binding"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type eventsServer struct {
+	pb.UnimplementedEventsServer
+	shutdown <-chan struct{}
+}
+
+func (s *eventsServer) ListEvents(ctx context.Context, req *pb.ListEventsRequest) (*pb.ListEventsResponse, error) {
+	list, err := models.GetEvents(models.EventFilter{
+		OrgID:    callerFrom(ctx).OrgID,
+		Category: req.Category,
+		Tags:     req.Tags,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve events: %v", err)
+	}
+
+	events := make([]*pb.Event, len(list))
+	for i, e := range list {
+		events[i] = eventToPB(e)
+	}
+
+	return &pb.ListEventsResponse{Events: events}, nil
+}
+
+func (s *eventsServer) GetEvent(ctx context.Context, req *pb.GetEventRequest) (*pb.Event, error) {
+	event, err := loadEvent(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	return eventToPB(*event), nil
+}
+
+func (s *eventsServer) CreateEvent(ctx context.Context, req *pb.CreateEventRequest) (*pb.Event, error) {
+	c := callerFrom(ctx)
+
+	// only members can create events in an organization
+	if c.OrgID != nil && c.OrgRole == "" {
+		return nil, status.Error(codes.PermissionDenied, "Only members of the organization can create events in it.")
+	}
+
+	e := eventFromPB(req.Event)
+	e.UserID = c.UserID
+	e.OrgID = c.OrgID
+
+	err := binding.Validator.ValidateStruct(&e)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = e.Save()
+	if err != nil {
+		return nil, eventWriteError("Could not create the event", err)
+	}
+
+	return eventToPB(e), nil
+}
+
+func (s *eventsServer) UpdateEvent(ctx context.Context, req *pb.UpdateEventRequest) (*pb.Event, error) {
+	event, err := loadEvent(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorize(ctx, event, permissions.UpdateEvent, "Only event hosts can update the info.")
+	if err != nil {
+		return nil, err
+	}
+
+	updatedEvent := eventFromPB(req.Event)
+	updatedEvent.ID = event.ID
+	updatedEvent.UserID = event.UserID
+	updatedEvent.Version = req.Version
+	updatedEvent.OrgID = event.OrgID
+	// the coordinates, end time and approval mode can only be set through REST, they stay as they are
+	updatedEvent.RequiresApproval = event.RequiresApproval
+	updatedEvent.Latitude, updatedEvent.Longitude = event.Latitude, event.Longitude
+	if event.EndsAt != nil {
+		// a new date moves the end along, the event keeps its length
+		endsAt := updatedEvent.DateTime.Add(event.EndsAt.Sub(event.DateTime))
+		updatedEvent.EndsAt = &endsAt
+	}
+
+	// nil tags keep the current ones, an empty slice removes them
+	if !req.Event.GetReplaceTags() {
+		updatedEvent.Tags = nil
+	} else if updatedEvent.Tags == nil {
+		updatedEvent.Tags = []string{}
+	}
+
+	err = binding.Validator.ValidateStruct(&updatedEvent)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	err = updatedEvent.Update()
+	if err != nil {
+		return nil, eventWriteError("Could not update the event", err)
+	}
+
+	return eventToPB(updatedEvent), nil
+}
+
+func (s *eventsServer) DeleteEvent(ctx context.Context, req *pb.DeleteEventRequest) (*pb.DeleteEventResponse, error) {
+	event, err := loadEvent(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorize(ctx, event, permissions.DeleteEvent, "Only event owners can delete the info.")
+	if err != nil {
+		return nil, err
+	}
+
+	event.Version = req.Version
+	err = event.Delete()
+	if err != nil {
+		return nil, eventWriteError("Could not delete the event", err)
+	}
+
+	return &pb.DeleteEventResponse{}, nil
+}
+
+// WatchEvents streams the changes of the caller's organization until the client
+// goes away or the server shuts down. A client that falls behind gets
+// RESOURCE_EXHAUSTED and should list the events again before watching anew.
+func (s *eventsServer) WatchEvents(req *pb.WatchEventsRequest, stream grpc.ServerStreamingServer[pb.EventChange]) error {
+	orgID := callerFrom(stream.Context()).OrgID
+
+	changes, cancel := models.SubscribeEventChanges()
+	defer cancel()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-s.shutdown:
+			return status.Error(codes.Unavailable, "The server is shutting down.")
+		case change, ok := <-changes:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "Fell behind the changes, list the events and watch again.")
+			}
+
+			if !sameOrg(change.Event.OrgID, orgID) {
+				continue
+			}
+
+			// only public events are listed, so only their changes are streamed, an
+			// event turning public or not public anymore comes and goes for watchers
+			kind := changeKinds[change.Kind]
+			switch {
+			case change.Event.Visibility != models.VisibilityPublic && change.PreviousVisibility == models.VisibilityPublic:
+				kind = pb.EventChange_KIND_DELETED
+			case change.Event.Visibility != models.VisibilityPublic:
+				continue
+			case change.PreviousVisibility != "":
+				kind = pb.EventChange_KIND_CREATED
+			}
+
+			err := stream.Send(&pb.EventChange{Kind: kind, Event: eventToPB(change.Event)})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func sameOrg(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// loadEvent finds the event inside the caller's organization, if the caller may see it.
+func loadEvent(ctx context.Context, id int64) (*models.Event, error) {
+	event, err := models.GetOrgEventByID(callerFrom(ctx).OrgID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "Event not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve the event: %v", err)
+	}
+
+	// private events the caller may not see answer NotFound like missing ones
+	allowed, err := models.CanViewEvent(callerFrom(ctx).UserID, event)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve the event: %v", err)
+	}
+	if !allowed {
+		return nil, status.Error(codes.NotFound, "Event not found")
+	}
+
+	return event, nil
+}
+
+// authorize checks the caller against the event's hosts.
+func authorize(ctx context.Context, event *models.Event, action permissions.Action, message string) error {
+	allowed, err := permissions.Can(callerFrom(ctx).UserID, event, action)
+	if err != nil {
+		return status.Errorf(codes.Internal, "Could not check the permissions: %v", err)
+	}
+	if !allowed {
+		return status.Error(codes.PermissionDenied, message)
+	}
+
+	return nil
+}
+
+// eventWriteError maps the errors of Save, Update and Delete to status codes.
+func eventWriteError(message string, err error) error {
+	if errors.Is(err, models.ErrUnknownCategory) || errors.Is(err, models.ErrInvalidCoordinates) || errors.Is(err, models.ErrInvalidEndTime) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+	if errors.Is(err, models.ErrVersionConflict) {
+		return status.Error(codes.Aborted, "The event was modified by someone else. Fetch it again and retry.")
+	}
+
+	return status.Errorf(codes.Internal, "%s: %v", message, err)
+}
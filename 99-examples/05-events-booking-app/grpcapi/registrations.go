@@ -0,0 +1,88 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"events-booking/models"
+	"events-booking/pb"
+	"events-booking/permissions"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type registrationsServer struct {
+	pb.UnimplementedRegistrationsServer
+}
+
+func (s *registrationsServer) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.Registration, error) {
+	userId := callerFrom(ctx).UserID
+	event, err := loadEvent(ctx, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	reg, err := event.Register(userId)
+	if errors.Is(err, models.ErrAlreadyRegistered) {
+		return nil, status.Error(codes.AlreadyExists, "You are already registered for this event.")
+	}
+	if errors.Is(err, models.ErrRegistrationRemoved) {
+		return nil, status.Error(codes.PermissionDenied, "The organizer removed you from this event, you can't sign up again.")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not register for the event: %v", err)
+	}
+
+	return registrationToPB(*reg), nil
+}
+
+func (s *registrationsServer) CancelRegistration(ctx context.Context, req *pb.CancelRegistrationRequest) (*pb.CancelRegistrationResponse, error) {
+	event, err := loadEvent(ctx, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	userId := callerFrom(ctx).UserID
+	err = event.CancelRegistration(userId, userId, "")
+	if errors.Is(err, models.ErrRegistrationNotFound) {
+		return nil, status.Error(codes.NotFound, "You are not registered for this event.")
+	}
+	if errors.Is(err, models.ErrInvalidTransition) {
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not delete the registration for the event: %v", err)
+	}
+
+	return &pb.CancelRegistrationResponse{}, nil
+}
+
+func (s *registrationsServer) ListMyRegistrations(ctx context.Context, req *pb.ListMyRegistrationsRequest) (*pb.ListRegistrationsResponse, error) {
+	userId := callerFrom(ctx).UserID
+
+	regs, err := models.GetRegistrationsByUsers([]int64{userId})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve the registrations: %v", err)
+	}
+
+	return registrationsToPB(regs[userId]), nil
+}
+
+func (s *registrationsServer) ListEventRegistrations(ctx context.Context, req *pb.ListEventRegistrationsRequest) (*pb.ListRegistrationsResponse, error) {
+	event, err := loadEvent(ctx, req.EventId)
+	if err != nil {
+		return nil, err
+	}
+
+	err = authorize(ctx, event, permissions.ViewAttendees, "Only event hosts can see the attendees.")
+	if err != nil {
+		return nil, err
+	}
+
+	regs, err := models.GetRegistrationsByEvents([]int64{event.ID})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve the registrations: %v", err)
+	}
+
+	return registrationsToPB(regs[event.ID]), nil
+}
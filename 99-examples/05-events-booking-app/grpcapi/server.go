@@ -0,0 +1,30 @@
+// Package grpcapi serves the Events, Users and Registrations gRPC services defined in
+// proto/eventsbooking/v1 for internal consumers. It runs next to the REST API on its own
+// port and calls the same models and permission checks.
+package grpcapi
+
+import (
+	"context"
+	"events-booking/pb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+)
+
+// NewServer registers the services. Streams end when ctx is cancelled, so
+// GracefulStop does not wait for WatchEvents clients forever.
+func NewServer(ctx context.Context) *grpc.Server {
+	server := grpc.NewServer(
+		grpc.UnaryInterceptor(unaryAuth),
+		grpc.StreamInterceptor(streamAuth),
+	)
+
+	pb.RegisterEventsServer(server, &eventsServer{shutdown: ctx.Done()})
+	pb.RegisterUsersServer(server, &usersServer{})
+	pb.RegisterRegistrationsServer(server, &registrationsServer{})
+
+	// lets tools like grpcurl list the services
+	reflection.Register(server)
+
+	return server
+}
@@ -0,0 +1,50 @@
+package grpcapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"events-booking/models"
+	"events-booking/pb"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type usersServer struct {
+	pb.UnimplementedUsersServer
+}
+
+func (s *usersServer) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	list, err := models.GetAllUsers()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve the users: %v", err)
+	}
+
+	users := make([]*pb.User, len(list))
+	for i, u := range list {
+		users[i] = userToPB(u)
+	}
+
+	return &pb.ListUsersResponse{Users: users}, nil
+}
+
+func (s *usersServer) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.User, error) {
+	return getUser(req.Id)
+}
+
+func (s *usersServer) GetCurrentUser(ctx context.Context, req *pb.GetCurrentUserRequest) (*pb.User, error) {
+	return getUser(callerFrom(ctx).UserID)
+}
+
+func getUser(id int64) (*pb.User, error) {
+	u, err := models.GetUserByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, status.Error(codes.NotFound, "User not found")
+	}
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "Could not retrieve the user: %v", err)
+	}
+
+	return userToPB(*u), nil
+}
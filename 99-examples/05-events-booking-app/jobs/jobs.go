@@ -0,0 +1,216 @@
+// Package jobs is a durable background job queue stored in the app's SQLite DB.
+//
+// Jobs can be enqueued in the same transaction as the model writes they belong to,
+// so a job exists exactly when its data was committed. Workers lease a job for a
+// visibility timeout and keep renewing the lease while the handler runs; a job whose
+// worker died becomes visible again once the lease runs out.
+package jobs
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"events-booking/db"
+	"time"
+)
+
+// Job states
+const (
+	StatusQueued  = "queued"
+	StatusRunning = "running"
+	StatusDone    = "done"
+	StatusFailed  = "failed"
+)
+
+const defaultMaxAttempts = 5
+
+var (
+	// ErrDuplicate is returned when a job with the same unique key is still queued or running.
+	ErrDuplicate   = errors.New("a job with this unique key is already pending")
+	ErrJobNotFound = errors.New("job not found")
+)
+
+type Job struct {
+	ID          int64           `json:"id"`
+	Kind        string          `json:"kind"`
+	Payload     json.RawMessage `json:"payload"`
+	Status      string          `json:"status"`
+	Attempts    int             `json:"attempts"`
+	MaxAttempts int             `json:"max_attempts"`
+	RunAt       time.Time       `json:"run_at"`
+	UniqueKey   *string         `json:"unique_key,omitempty"`
+	LastError   *string         `json:"last_error,omitempty"`
+	CreatedAt   time.Time       `json:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at"`
+
+	lease string
+}
+
+// Decode unmarshals the job payload.
+func (j *Job) Decode(v any) error {
+	return json.Unmarshal(j.Payload, v)
+}
+
+// NewJob describes a job to enqueue, only Kind is required.
+type NewJob struct {
+	Kind        string
+	Payload     any
+	RunAt       time.Time // zero means now
+	UniqueKey   string    // at most one queued or running job per key
+	MaxAttempts int       // zero means 5
+}
+
+// execer is implemented by both *sql.DB and *sql.Tx
+type execer interface {
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// Enqueue adds the job on its own.
+func Enqueue(job NewJob) (int64, error) {
+	return enqueue(db.DB, job)
+}
+
+// EnqueueTx adds the job inside tx, it is only visible to workers once tx commits.
+func EnqueueTx(tx *sql.Tx, job NewJob) (int64, error) {
+	return enqueue(tx, job)
+}
+
+func enqueue(ex execer, job NewJob) (int64, error) {
+	payload, err := json.Marshal(job.Payload)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	runAt := job.RunAt
+	if runAt.IsZero() {
+		runAt = now
+	}
+
+	maxAttempts := job.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	var uniqueKey *string
+	if job.UniqueKey != "" {
+		uniqueKey = &job.UniqueKey
+	}
+
+	query := `
+	INSERT INTO jobs (kind, payload, status, attempts, max_attempts, run_at, unique_key, created_at, updated_at)
+	VALUES (?, ?, ?, 0, ?, ?, ?, ?, ?)
+	ON CONFLICT DO NOTHING`
+
+	result, err := ex.Exec(query, job.Kind, string(payload), StatusQueued, maxAttempts,
+		runAt.UnixMilli(), uniqueKey, now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if affected == 0 {
+		return 0, ErrDuplicate
+	}
+
+	return result.LastInsertId()
+}
+
+// times are stored as unix milliseconds so they compare correctly in SQL
+const jobColumns = "id, kind, payload, status, attempts, max_attempts, run_at, unique_key, last_error, created_at, updated_at"
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanJob(row scanner, j *Job) error {
+	var payload string
+	var runAt, createdAt, updatedAt int64
+
+	err := row.Scan(&j.ID, &j.Kind, &payload, &j.Status, &j.Attempts, &j.MaxAttempts,
+		&runAt, &j.UniqueKey, &j.LastError, &createdAt, &updatedAt)
+	if err != nil {
+		return err
+	}
+
+	j.Payload = json.RawMessage(payload)
+	j.RunAt = time.UnixMilli(runAt).UTC()
+	j.CreatedAt = time.UnixMilli(createdAt).UTC()
+	j.UpdatedAt = time.UnixMilli(updatedAt).UTC()
+
+	return nil
+}
+
+// List returns the most recently updated jobs with the given status.
+func List(status string, limit int) ([]Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE status = ? ORDER BY updated_at DESC LIMIT ?"
+
+	rows, err := db.DB.Query(query, status, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []Job{}
+
+	for rows.Next() {
+		var j Job
+		err := scanJob(rows, &j)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, j)
+	}
+
+	return list, nil
+}
+
+func Get(id int64) (*Job, error) {
+	query := "SELECT " + jobColumns + " FROM jobs WHERE id = ?"
+
+	var j Job
+	err := scanJob(db.DB.QueryRow(query, id), &j)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrJobNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &j, nil
+}
+
+// Retry queues a failed job again with a fresh set of attempts. It fails with
+// ErrDuplicate while another job with the same unique key is pending.
+func Retry(id int64) error {
+	// OR IGNORE skips the row instead of failing on jobs_unique_key
+	query := `
+	UPDATE OR IGNORE jobs SET status = ?, attempts = 0, run_at = ?, updated_at = ?
+	WHERE id = ? AND status = ?`
+
+	now := time.Now().UnixMilli()
+	result, err := db.DB.Exec(query, StatusQueued, now, now, id, StatusFailed)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		job, err := Get(id)
+		if err != nil {
+			return err
+		}
+		if job.Status == StatusFailed {
+			return ErrDuplicate
+		}
+		return ErrJobNotFound
+	}
+
+	return nil
+}
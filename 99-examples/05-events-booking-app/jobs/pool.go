@@ -0,0 +1,261 @@
+package jobs
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"events-booking/db"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Handler runs one job. Returning an error retries the job with backoff
+// until it runs out of attempts. ctx is cancelled when the lease is lost
+// or the pool shuts down.
+type Handler func(ctx context.Context, job *Job) error
+
+type Pool struct {
+	handlers     map[string]Handler
+	concurrency  int
+	visibility   time.Duration
+	pollInterval time.Duration
+}
+
+const minVisibility = time.Second
+
+// NewPool runs up to concurrency jobs at once. A job stays invisible to other
+// workers for the visibility timeout, which is renewed while the handler runs.
+func NewPool(concurrency int, visibility time.Duration, pollInterval time.Duration) *Pool {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	// leases are stored in milliseconds and renewed every third of the timeout
+	if visibility < minVisibility {
+		visibility = minVisibility
+	}
+	if pollInterval <= 0 {
+		pollInterval = time.Second
+	}
+
+	return &Pool{
+		handlers:     map[string]Handler{},
+		concurrency:  concurrency,
+		visibility:   visibility,
+		pollInterval: pollInterval,
+	}
+}
+
+// Handle registers the handler for a job kind, it must be called before Run.
+func (p *Pool) Handle(kind string, handler Handler) {
+	p.handlers[kind] = handler
+}
+
+// Run starts the workers and blocks until ctx is cancelled and the running jobs returned.
+func (p *Pool) Run(ctx context.Context) {
+	if len(p.handlers) == 0 {
+		return
+	}
+
+	var workers sync.WaitGroup
+	for i := 0; i < p.concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			p.work(ctx)
+		}()
+	}
+
+	workers.Wait()
+}
+
+func (p *Pool) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		job, err := p.claim()
+		if err != nil {
+			log.Printf("[jobs] could not claim a job: %v", err)
+		}
+
+		if job == nil {
+			select {
+			case <-ctx.Done():
+			case <-time.After(p.pollInterval):
+			}
+			continue
+		}
+
+		p.process(ctx, job)
+	}
+}
+
+// claim leases the next due job, including running jobs whose lease ran out.
+func (p *Pool) claim() (*Job, error) {
+	kinds := make([]any, 0, len(p.handlers))
+	for kind := range p.handlers {
+		kinds = append(kinds, kind)
+	}
+
+	lease, err := newLease()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	query := `
+	UPDATE jobs SET status = ?, attempts = attempts + 1, lease = ?, locked_until = ?, updated_at = ?
+	WHERE id = (
+		SELECT id FROM jobs
+		WHERE kind IN (?` + strings.Repeat(", ?", len(kinds)-1) + `)
+		AND ((status = ? AND run_at <= ?) OR (status = ? AND locked_until < ?))
+		ORDER BY run_at, id
+		LIMIT 1
+	)
+	RETURNING ` + jobColumns
+
+	args := []any{StatusRunning, lease, now.Add(p.visibility).UnixMilli(), now.UnixMilli()}
+	args = append(args, kinds...)
+	args = append(args, StatusQueued, now.UnixMilli(), StatusRunning, now.UnixMilli())
+
+	var job Job
+	err = scanJob(db.DB.QueryRow(query, args...), &job)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	job.lease = lease
+
+	return &job, nil
+}
+
+func (p *Pool) process(ctx context.Context, job *Job) {
+	if job.Attempts > job.MaxAttempts {
+		// the lease ran out on the last attempt, e.g. the worker crashed
+		p.finish(job, errors.New("lease expired on the last attempt"), false)
+		return
+	}
+
+	jobCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	renewed := make(chan struct{})
+	go func() {
+		defer close(renewed)
+		p.renewLease(jobCtx, cancel, job)
+	}()
+
+	err := p.run(jobCtx, job)
+	cancel()
+	<-renewed
+
+	if err != nil && ctx.Err() != nil {
+		// shutting down, put the job back without using up an attempt
+		p.release(job)
+		return
+	}
+
+	p.finish(job, err, true)
+}
+
+// run calls the handler, turning a panic into an error so one job can't kill the worker.
+func (p *Pool) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		recovered := recover()
+		if recovered != nil {
+			err = fmt.Errorf("panic: %v", recovered)
+		}
+	}()
+
+	return p.handlers[job.Kind](ctx, job)
+}
+
+// renewLease extends the lease while the job runs and cancels the job if another worker took it over.
+func (p *Pool) renewLease(ctx context.Context, cancel context.CancelFunc, job *Job) {
+	ticker := time.NewTicker(p.visibility / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		result, err := db.DB.Exec(`UPDATE jobs SET locked_until = ? WHERE id = ? AND lease = ?`,
+			time.Now().Add(p.visibility).UnixMilli(), job.ID, job.lease)
+		if err != nil {
+			log.Printf("[jobs] could not renew the lease of job %d: %v", job.ID, err)
+			continue
+		}
+
+		affected, err := result.RowsAffected()
+		if err == nil && affected == 0 {
+			log.Printf("[jobs] lost the lease of job %d", job.ID)
+			cancel()
+			return
+		}
+	}
+}
+
+// finish marks the job done, or queues it again with backoff while it has attempts left.
+func (p *Pool) finish(job *Job, jobErr error, retry bool) {
+	now := time.Now()
+	var err error
+
+	switch {
+	case jobErr == nil:
+		_, err = db.DB.Exec(`
+		UPDATE jobs SET status = ?, lease = NULL, locked_until = NULL, last_error = NULL, updated_at = ?
+		WHERE id = ? AND lease = ?`, StatusDone, now.UnixMilli(), job.ID, job.lease)
+
+	case retry && job.Attempts < job.MaxAttempts:
+		_, err = db.DB.Exec(`
+		UPDATE jobs SET status = ?, lease = NULL, locked_until = NULL, last_error = ?, run_at = ?, updated_at = ?
+		WHERE id = ? AND lease = ?`, StatusQueued, jobErr.Error(), now.Add(Backoff(job.Attempts)).UnixMilli(),
+			now.UnixMilli(), job.ID, job.lease)
+
+	default:
+		log.Printf("[jobs] job %d (%s) failed for good: %v", job.ID, job.Kind, jobErr)
+		_, err = db.DB.Exec(`
+		UPDATE jobs SET status = ?, lease = NULL, locked_until = NULL, last_error = ?, updated_at = ?
+		WHERE id = ? AND lease = ?`, StatusFailed, jobErr.Error(), now.UnixMilli(), job.ID, job.lease)
+	}
+
+	if err != nil {
+		log.Printf("[jobs] could not update job %d: %v", job.ID, err)
+	}
+}
+
+func (p *Pool) release(job *Job) {
+	_, err := db.DB.Exec(`
+	UPDATE jobs SET status = ?, attempts = attempts - 1, lease = NULL, locked_until = NULL, updated_at = ?
+	WHERE id = ? AND lease = ?`, StatusQueued, time.Now().UnixMilli(), job.ID, job.lease)
+	if err != nil {
+		log.Printf("[jobs] could not release job %d: %v", job.ID, err)
+	}
+}
+
+// Backoff is the delay before the next attempt: 10s, 20s, 40s... capped at one hour.
+func Backoff(attempts int) time.Duration {
+	delay := 10 * time.Second
+	for i := 1; i < attempts && delay < time.Hour; i++ {
+		delay *= 2
+	}
+
+	return min(delay, time.Hour)
+}
+
+func newLease() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
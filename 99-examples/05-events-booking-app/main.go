@@ -1,17 +1,155 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"events-booking/blob"
+	"events-booking/cache"
+	"events-booking/config"
 	db "events-booking/db"
+	"events-booking/grpcapi"
+	"events-booking/jobs"
+	"events-booking/models"
+	"events-booking/notify"
+	"events-booking/reminders"
 	"events-booking/routes"
+	"events-booking/sso"
+	"events-booking/tasks"
+	"events-booking/utils"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
 func main() {
+	cfg := config.Load()
+
 	db.InitDB() // Initialize the database connection
+
+	keys, err := loadKeyset(cfg)
+	if err != nil {
+		log.Fatalf("Could not load the JWT keys: %v", err)
+	}
+	utils.ConfigureJWT(utils.JWTConfig{Keys: keys, Issuer: cfg.JWTIssuer, Audience: cfg.JWTAudience, Leeway: cfg.JWTLeeway})
+
+	err = utils.ConfigurePasswordHashing(utils.PasswordHashing{
+		Algorithm:         cfg.PasswordHash,
+		BcryptCost:        cfg.BcryptCost,
+		Argon2Memory:      uint32(cfg.Argon2Memory),
+		Argon2Iterations:  uint32(cfg.Argon2Iterations),
+		Argon2Parallelism: uint8(cfg.Argon2Parallelism),
+	})
+	if err != nil {
+		log.Fatalf("Could not set up password hashing: %v", err)
+	}
+	utils.SetPasswordMinLength(cfg.PasswordMinLength)
+	utils.SetTOTPIssuer(cfg.TOTPIssuer)
+
+	if cfg.CacheSize > 0 {
+		models.SetEventCache(cache.NewLRU(cfg.CacheSize, cfg.CacheTTL))
+	}
+	if cfg.SessionCacheSize > 0 {
+		models.SetSessionCache(cache.NewLRU(cfg.SessionCacheSize, cfg.SessionCacheTTL))
+	}
+
+	blobs, err := blob.NewFSStore(cfg.BlobDir)
+	if err != nil {
+		log.Fatalf("Could not open the blob store: %v", err)
+	}
+	models.SetBlobStore(blobs)
+	routes.SetAttachmentMaxSize(cfg.AttachmentMaxSize)
+
 	server := gin.Default()
 
 	routes.RegisterRoutes(server)
 
-	server.Run(":8080") // Start the server on port 8080 - domain auto decided by OS
+	// cancelled on Ctrl+C or SIGTERM, background workers stop with it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.OIDCIssuer != "" {
+		provider, err := sso.New(ctx, sso.Config{
+			Issuer:       cfg.OIDCIssuer,
+			ClientID:     cfg.OIDCClientID,
+			ClientSecret: cfg.OIDCClientSecret,
+			RedirectURL:  cfg.OIDCRedirectURL,
+		})
+		if err != nil {
+			log.Fatalf("Could not set up single sign-on: %v", err)
+		}
+		routes.EnableSSO(provider, cfg.OIDCTrustMFA)
+	}
+
+	notifier, err := notify.New(cfg.Notifier, cfg.NotifyFile, cfg.NotifyWebhookURL)
+	if err != nil {
+		log.Fatalf("Could not set up notifications: %v", err)
+	}
+
+	var workers sync.WaitGroup
+	scheduler := reminders.NewScheduler(notifier, cfg.ReminderOffsets, cfg.ReminderInterval)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		scheduler.Run(ctx)
+	}()
+
+	pool := jobs.NewPool(cfg.JobConcurrency, cfg.JobVisibilityTimeout, cfg.JobPollInterval)
+	tasks.Register(pool, notifier)
+	workers.Add(1)
+	go func() {
+		defer workers.Done()
+		pool.Run(ctx)
+	}()
+
+	// http.Server instead of server.Run so it can be shut down gracefully
+	httpServer := &http.Server{Addr: ":" + cfg.Port, Handler: server}
+	go func() {
+		err := httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Fatalf("Could not start the server: %v", err)
+		}
+	}()
+
+	// gRPC for internal services, on its own port next to REST
+	grpcServer := grpcapi.NewServer(ctx)
+	listener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		log.Fatalf("Could not listen for gRPC: %v", err)
+	}
+	go func() {
+		err := grpcServer.Serve(listener)
+		if err != nil {
+			log.Fatalf("Could not start the gRPC server: %v", err)
+		}
+	}()
+
+	<-ctx.Done()
+	log.Println("Shutting down...")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	err = httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		log.Printf("Could not shut down the server cleanly: %v", err)
+	}
+
+	grpcServer.GracefulStop()
+
+	workers.Wait()
+}
+
+func loadKeyset(cfg config.Config) (*utils.Keyset, error) {
+	if cfg.JWTSigningKey == "" {
+		log.Println("JWT_SIGNING_KEY is not set, signing with a throwaway key: tokens stop working on restart")
+		return utils.GenerateKeyset()
+	}
+	return utils.LoadKeyset(cfg.JWTSigningKey, cfg.JWTRetiringKeys)
 }
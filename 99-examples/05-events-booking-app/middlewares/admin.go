@@ -0,0 +1,24 @@
+package middlewares
+
+import (
+	"events-booking/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequireAdmin has to run after Authenticate, it needs the userId on the context.
+func RequireAdmin(c *gin.Context) {
+	isAdmin, err := models.IsAdmin(c.GetInt64("userId"))
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Could not check the permissions.", "error": err.Error()})
+		return
+	}
+
+	if !isAdmin {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "Only admins can do this."})
+		return
+	}
+
+	c.Next()
+}
@@ -0,0 +1,53 @@
+package middlewares
+
+import (
+	"errors"
+	"events-booking/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Scopes names the API key scope the routes after it need: read for GET and HEAD,
+// write for everything else. It has to run before (Optional)Authenticate.
+// Routes without Scopes can't be called with an API key at all.
+func Scopes(read string, write string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		scope := write
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			scope = read
+		}
+		c.Set("requiredScope", scope)
+
+		c.Next()
+	}
+}
+
+// authenticateAPIKey sets the userId of the key's owner when the key has the route's scope.
+func authenticateAPIKey(c *gin.Context, plain string) {
+	scope := c.GetString("requiredScope")
+	if scope == "" {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "API keys can't be used for this endpoint."})
+		return
+	}
+
+	key, err := models.AuthenticateAPIKey(plain)
+	if errors.Is(err, models.ErrInvalidAPIKey) {
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid API key"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Could not check the API key.", "error": err.Error()})
+		return
+	}
+
+	if !key.HasScope(scope) {
+		c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"message": "The API key lacks the " + scope + " scope."})
+		return
+	}
+
+	c.Set("userId", key.UserID)
+	c.Set("apiKeyId", key.ID)
+
+	c.Next()
+}
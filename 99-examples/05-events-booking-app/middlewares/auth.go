@@ -1,21 +1,30 @@
 package middlewares
 
 import (
-	"events-booking/utils"
+	"events-booking/models"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 )
 
+// Authenticate accepts a JWT in the Authorization header ("Bearer " is optional)
+// or an API key in X-API-Key, which also needs the scope named by Scopes.
 func Authenticate(c *gin.Context) {
-	token := c.Request.Header.Get("Authorization")
+	apiKey := c.GetHeader("X-API-Key")
+	if apiKey != "" {
+		authenticateAPIKey(c, apiKey)
+		return
+	}
+
+	token := bearerToken(c)
 
 	if token == "" {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Not Authorized"})
 		return
 	}
 
-	userId, err := utils.VerifyJwtToken(token)
+	userId, sessionId, err := models.AuthenticateToken(token)
 	if err != nil {
 		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid Token"})
 		return
@@ -23,7 +32,36 @@ func Authenticate(c *gin.Context) {
 
 	// sets the value on the context
 	c.Set("userId", userId)
+	c.Set("sessionId", sessionId)
 
 	// allows the next handler for the request to get triggered
 	c.Next()
 }
+
+// OptionalAuthenticate sets the userId when a valid token is sent, but lets
+// anonymous requests through, for public pages that show more to logged in users.
+func OptionalAuthenticate(c *gin.Context) {
+	apiKey := c.GetHeader("X-API-Key")
+	if apiKey != "" {
+		authenticateAPIKey(c, apiKey)
+		return
+	}
+
+	token := bearerToken(c)
+
+	if token != "" {
+		userId, sessionId, err := models.AuthenticateToken(token)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"message": "Invalid Token"})
+			return
+		}
+		c.Set("userId", userId)
+		c.Set("sessionId", sessionId)
+	}
+
+	c.Next()
+}
+
+func bearerToken(c *gin.Context) string {
+	return strings.TrimPrefix(c.Request.Header.Get("Authorization"), "Bearer ")
+}
@@ -0,0 +1,128 @@
+package middlewares
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"events-booking/models"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// how long a stored response is replayed
+	IdempotencyTTL = 24 * time.Hour
+	// a request still running after this long is considered dead and its key is freed
+	idempotencyLock = time.Minute
+	// the body is read into memory to hash it, uploads don't run behind this middleware
+	maxIdempotentBody = 1 << 20
+)
+
+// responseRecorder keeps a copy of everything the handler writes.
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) WriteString(s string) (int, error) {
+	r.body.WriteString(s)
+	return r.ResponseWriter.WriteString(s)
+}
+
+// Idempotency makes POST requests with an Idempotency-Key header safe to retry.
+// The first response is stored per user and key and replayed verbatim for retries.
+// A retry with a different body gets 422, one that arrives while the first
+// request is still running gets 409. It has to run after Authenticate.
+func Idempotency(c *gin.Context) {
+	key := c.GetHeader("Idempotency-Key")
+	if key == "" || c.Request.Method != http.MethodPost {
+		c.Next()
+		return
+	}
+
+	if len(key) > 255 {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Idempotency-Key must be at most 255 characters."})
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxIdempotentBody))
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("Requests with an Idempotency-Key must be at most %d bytes.", maxIdempotentBody)})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"message": "Could not read the request body.", "error": err.Error()})
+		return
+	}
+	// the handler still needs to read the body
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+	hash := sha256.New()
+	// the X-Org header picks the tenant, so it belongs to the request too
+	hash.Write([]byte(c.Request.Method + " " + c.Request.URL.Path + " " + c.GetHeader("X-Org") + "\n"))
+	hash.Write(body)
+	requestHash := hex.EncodeToString(hash.Sum(nil))
+
+	userId := c.GetInt64("userId")
+
+	err = models.DeleteExpiredIdempotencyKeys()
+	if err != nil {
+		log.Printf("[idempotency] could not delete expired keys: %v", err)
+	}
+
+	stored, err := models.ClaimIdempotencyKey(userId, key, requestHash, idempotencyLock, IdempotencyTTL)
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Could not check the Idempotency-Key.", "error": err.Error()})
+		return
+	}
+
+	if stored != nil {
+		replay(c, stored, requestHash)
+		return
+	}
+
+	recorder := &responseRecorder{ResponseWriter: c.Writer}
+	c.Writer = recorder
+
+	c.Next()
+
+	status := recorder.Status()
+	if status >= http.StatusInternalServerError {
+		// server errors are not final, let the client retry for real
+		err = models.ReleaseIdempotencyKey(userId, key)
+	} else {
+		err = models.SaveIdempotentResponse(userId, key, status, recorder.Header().Get("Content-Type"), recorder.body.Bytes())
+	}
+	if err != nil {
+		log.Printf("[idempotency] could not store the response for key %q: %v", key, err)
+	}
+}
+
+func replay(c *gin.Context, stored *models.IdempotentResponse, requestHash string) {
+	if stored.RequestHash != requestHash {
+		c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"message": "This Idempotency-Key was already used with a different request."})
+		return
+	}
+
+	if stored.StatusCode == 0 {
+		c.Header("Retry-After", "1")
+		c.AbortWithStatusJSON(http.StatusConflict, gin.H{"message": "A request with this Idempotency-Key is still being processed."})
+		return
+	}
+
+	c.Header("Idempotent-Replayed", "true")
+	c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+	c.Abort()
+}
@@ -0,0 +1,55 @@
+package middlewares
+
+import (
+	"errors"
+	"events-booking/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tenant selects the active organization from the /orgs/:org path prefix or the
+// X-Org header (the org slug). Without either the request works on the events
+// outside any organization. Members-only organizations answer 404 to non-members,
+// so their existence isn't revealed. It has to run after (Optional)Authenticate.
+func Tenant(c *gin.Context) {
+	slug := c.Param("org")
+	if slug == "" {
+		slug = c.GetHeader("X-Org")
+	}
+
+	if slug == "" {
+		c.Next()
+		return
+	}
+
+	org, err := models.GetOrganizationBySlug(slug)
+	if errors.Is(err, models.ErrOrgNotFound) {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Organization not found"})
+		return
+	}
+	if err != nil {
+		c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the organization.", "error": err.Error()})
+		return
+	}
+
+	role := ""
+	userId := c.GetInt64("userId")
+	if userId != 0 {
+		role, err = models.GetOrgRole(org.ID, userId)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"message": "Could not check the membership.", "error": err.Error()})
+			return
+		}
+	}
+
+	if org.Visibility == models.OrgMembersOnly && role == "" {
+		c.AbortWithStatusJSON(http.StatusNotFound, gin.H{"message": "Organization not found"})
+		return
+	}
+
+	c.Set("org", org)
+	c.Set("orgRole", role)
+
+	c.Next()
+}
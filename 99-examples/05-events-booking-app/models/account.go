@@ -0,0 +1,416 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"events-booking/utils"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrWrongPassword   = errors.New("current password is wrong")
+	ErrInvalidTimezone = errors.New("unknown timezone")
+)
+
+// NotificationPrefs turns kinds of notifications on or off, everything is on for new users.
+// The first two are emails, the others go to the in-app inbox.
+type NotificationPrefs struct {
+	RegistrationConfirmations bool `json:"registration_confirmations"`
+	EventReminders            bool `json:"event_reminders"`
+	EventUpdates              bool `json:"event_updates"`
+	EventReschedules          bool `json:"event_reschedules"`
+	EventCancellations        bool `json:"event_cancellations"`
+}
+
+// Profile is the part of the user the user manages themselves.
+type Profile struct {
+	ID                int64             `json:"id"`
+	Email             string            `json:"email"`
+	DisplayName       string            `json:"display_name" binding:"max=100"`
+	Timezone          string            `json:"timezone"` // IANA name, e.g. Europe/Berlin
+	NotificationPrefs NotificationPrefs `json:"notification_prefs"`
+}
+
+func GetProfile(userID int64) (*Profile, error) {
+	query := `
+	SELECT id, email, display_name, timezone, notify_confirmations, notify_reminders,
+		notify_event_updates, notify_event_reschedules, notify_event_cancellations
+	FROM users WHERE id = ? AND deleted_at IS NULL`
+
+	var p Profile
+	prefs := &p.NotificationPrefs
+	err := db.DB.QueryRow(query, userID).Scan(&p.ID, &p.Email, &p.DisplayName, &p.Timezone,
+		&prefs.RegistrationConfirmations, &prefs.EventReminders,
+		&prefs.EventUpdates, &prefs.EventReschedules, &prefs.EventCancellations)
+	if err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// Update saves everything but the email.
+func (p Profile) Update() error {
+	_, err := time.LoadLocation(p.Timezone)
+	if err != nil || p.Timezone == "" || p.Timezone == "Local" {
+		return ErrInvalidTimezone
+	}
+
+	query := `
+	UPDATE users SET display_name = ?, timezone = ?, notify_confirmations = ?, notify_reminders = ?,
+		notify_event_updates = ?, notify_event_reschedules = ?, notify_event_cancellations = ?
+	WHERE id = ? AND deleted_at IS NULL`
+
+	prefs := p.NotificationPrefs
+	_, err = db.DB.Exec(query, p.DisplayName, p.Timezone,
+		prefs.RegistrationConfirmations, prefs.EventReminders,
+		prefs.EventUpdates, prefs.EventReschedules, prefs.EventCancellations, p.ID)
+	return err
+}
+
+// WantsNotification reports whether the user has the kind of notification turned on.
+func WantsNotification(userID int64, kind string) (bool, error) {
+	profile, err := GetProfile(userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		// deleted accounts get nothing
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	switch kind {
+	case NotifyRegistrationConfirmation:
+		return profile.NotificationPrefs.RegistrationConfirmations, nil
+	case NotifyEventReminder:
+		return profile.NotificationPrefs.EventReminders, nil
+	case NotifyEventUpdated:
+		return profile.NotificationPrefs.EventUpdates, nil
+	case NotifyEventRescheduled:
+		return profile.NotificationPrefs.EventReschedules, nil
+	case NotifyEventCancelled:
+		return profile.NotificationPrefs.EventCancellations, nil
+	}
+	return true, nil
+}
+
+// Kinds of notifications sent to users
+const (
+	NotifyRegistrationConfirmation = "registration_confirmation"
+	NotifyEventReminder            = "event_reminder"
+	NotifyEventUpdated             = "event_updated"
+	NotifyEventRescheduled         = "event_rescheduled"
+	NotifyEventCancelled           = "event_cancelled"
+)
+
+// ChangePassword checks the current password, stores the new one and logs out
+// every session but the caller's.
+func ChangePassword(userID int64, sessionID int64, currentPassword string, newPassword string) error {
+	var email, hashedPassword string
+	err := db.DB.QueryRow(`SELECT email, password FROM users WHERE id = ? AND deleted_at IS NULL`, userID).
+		Scan(&email, &hashedPassword)
+	if err != nil {
+		return err
+	}
+
+	if !utils.CheckValidHashPassword(currentPassword, hashedPassword) {
+		return ErrWrongPassword
+	}
+
+	err = utils.ValidatePassword(newPassword, email)
+	if err != nil {
+		return err
+	}
+
+	newHash, err := utils.HashNewPassword(newPassword)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DB.Exec(`UPDATE users SET password = ? WHERE id = ?`, newHash, userID)
+	if err != nil {
+		return err
+	}
+
+	return RevokeOtherSessions(userID, sessionID)
+}
+
+// UserExport is everything stored about a user, for GET /me/export.
+type UserExport struct {
+	ExportedAt    time.Time      `json:"exported_at"`
+	Profile       Profile        `json:"profile"`
+	Events        []Event        `json:"events"` // owned by the user
+	Hosting       []ExportedHost `json:"hosting"`
+	Registrations []Registration `json:"registrations"`
+	Organizations []ExportedOrg  `json:"organizations"`
+	APIKeys       []APIKey       `json:"api_keys"`
+	Sessions      []Session      `json:"sessions"`
+	MFA           MFAStatus      `json:"mfa"`
+	Notifications []Notification `json:"notifications"`
+}
+
+type ExportedHost struct {
+	EventID int64  `json:"event_id"`
+	Role    string `json:"role"`
+	Status  string `json:"status"`
+}
+
+type ExportedOrg struct {
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+func ExportUserData(userID int64) (*UserExport, error) {
+	profile, err := GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export := UserExport{ExportedAt: time.Now().UTC(), Profile: *profile}
+
+	export.Events, err = queryEvents("user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.DB.Query(`SELECT event_id, role, status FROM event_hosts WHERE user_id = ? AND role != ?`, userID, RoleOwner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	export.Hosting = []ExportedHost{}
+	for rows.Next() {
+		var h ExportedHost
+		err := rows.Scan(&h.EventID, &h.Role, &h.Status)
+		if err != nil {
+			return nil, err
+		}
+		export.Hosting = append(export.Hosting, h)
+	}
+
+	registrations, err := GetRegistrationsByUsers([]int64{userID})
+	if err != nil {
+		return nil, err
+	}
+	export.Registrations = registrations[userID]
+	if export.Registrations == nil {
+		export.Registrations = []Registration{}
+	}
+
+	orgRows, err := db.DB.Query(`
+	SELECT o.slug, o.name, m.role FROM org_members m JOIN organizations o ON o.id = m.org_id
+	WHERE m.user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer orgRows.Close()
+
+	export.Organizations = []ExportedOrg{}
+	for orgRows.Next() {
+		var o ExportedOrg
+		err := orgRows.Scan(&o.Slug, &o.Name, &o.Role)
+		if err != nil {
+			return nil, err
+		}
+		export.Organizations = append(export.Organizations, o)
+	}
+
+	export.APIKeys, err = GetUserAPIKeys(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export.Sessions, err = GetUserSessions(userID, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	mfa, err := GetMFAStatus(userID)
+	if err != nil {
+		return nil, err
+	}
+	export.MFA = *mfa
+
+	export.Notifications, _, err = GetNotifications(userID, false, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return &export, nil
+}
+
+// DeleteAccount anonymizes the user and hands over or cancels their events:
+//   - an owned event with an accepted co-host goes to the co-host who joined first
+//   - an upcoming owned event without one is deleted, with its registrations,
+//     and its registrants are notified like Event.Delete does
+//   - a past owned event without one stays, under the anonymized account
+//
+// The user's other host roles, upcoming registrations, access to private events,
+// organization memberships, API keys, SSO links, second factors and sessions are
+// removed, their comments are deleted like Comment.Delete does and their reviews
+// are cleared, the ratings stay. Fails with ErrLastOrgAdmin while the user is the
+// only admin of an organization with other members.
+func DeleteAccount(userID int64) error {
+	owned, err := queryEvents("user_id = ?", userID)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var lastAdminOf int
+	err = tx.QueryRow(`
+	SELECT COUNT(*) FROM org_members m
+	WHERE m.user_id = ? AND m.role = ?
+		AND NOT EXISTS (SELECT 1 FROM org_members a WHERE a.org_id = m.org_id AND a.role = ? AND a.user_id != m.user_id)
+		AND EXISTS (SELECT 1 FROM org_members o WHERE o.org_id = m.org_id AND o.user_id != m.user_id)`,
+		userID, OrgRoleAdmin, OrgRoleAdmin).Scan(&lastAdminOf)
+	if err != nil {
+		return err
+	}
+	if lastAdminOf > 0 {
+		return ErrLastOrgAdmin
+	}
+
+	now := time.Now().UTC()
+	var changed []EventChange
+	var blobKeys []string
+
+	for _, e := range owned {
+		var successor int64
+		err := tx.QueryRow(`
+		SELECT user_id FROM event_hosts WHERE event_id = ? AND user_id != ? AND role = ? AND status = ?
+		ORDER BY created_at, rowid LIMIT 1`, e.ID, userID, RoleCoHost, HostAccepted).Scan(&successor)
+
+		switch {
+		case err == nil:
+			_, err = tx.Exec(`UPDATE event_hosts SET role = ? WHERE event_id = ? AND user_id = ?`, RoleOwner, e.ID, successor)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`DELETE FROM event_hosts WHERE event_id = ? AND user_id = ?`, e.ID, userID)
+			if err != nil {
+				return err
+			}
+			_, err = tx.Exec(`UPDATE events SET user_id = ?, version = version + 1 WHERE id = ?`, successor, e.ID)
+			if err != nil {
+				return err
+			}
+			e.UserID = successor
+			e.Version++
+			changed = append(changed, EventChange{Kind: EventUpdated, Event: e})
+		case !errors.Is(err, sql.ErrNoRows):
+			return err
+		case e.DateTime.After(now):
+			err = notifyRegistrants(tx, e, NotifyEventCancelled, fmt.Sprintf("%s was cancelled.", e.Name))
+			if err != nil {
+				return fmt.Errorf("could not cancel event %d: %w", e.ID, err)
+			}
+			for _, query := range []string{
+				`DELETE FROM registration_events WHERE registration_id IN (SELECT id FROM registrations WHERE event_id = ?)`,
+				`DELETE FROM registrations WHERE event_id = ?`,
+				`DELETE FROM event_hosts WHERE event_id = ?`,
+				`DELETE FROM event_tags WHERE event_id = ?`,
+				`DELETE FROM events WHERE id = ?`,
+			} {
+				_, err = tx.Exec(query, e.ID)
+				if err != nil {
+					return fmt.Errorf("could not cancel event %d: %w", e.ID, err)
+				}
+			}
+			err = deleteEventInvites(tx, e.ID)
+			if err != nil {
+				return fmt.Errorf("could not cancel event %d: %w", e.ID, err)
+			}
+			err = deleteEventComments(tx, e.ID)
+			if err != nil {
+				return fmt.Errorf("could not cancel event %d: %w", e.ID, err)
+			}
+			keys, err := deleteEventAttachments(tx, e.ID)
+			if err != nil {
+				return fmt.Errorf("could not cancel event %d: %w", e.ID, err)
+			}
+			blobKeys = append(blobKeys, keys...)
+			changed = append(changed, EventChange{Kind: EventDeleted, Event: e})
+		}
+	}
+
+	// past events kept above still need their owner row
+	_, err = tx.Exec(`DELETE FROM event_hosts WHERE user_id = ? AND role != ?`, userID, RoleOwner)
+	if err != nil {
+		return err
+	}
+
+	upcoming := `SELECT id FROM registrations WHERE user_id = ? AND event_id IN (SELECT id FROM events WHERE date > ?)`
+	_, err = tx.Exec(`DELETE FROM registration_events WHERE registration_id IN (`+upcoming+`)`, userID, now)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM registrations WHERE id IN (`+upcoming+`)`, userID, now)
+	if err != nil {
+		return err
+	}
+
+	// the comments stay for their replies, without the text
+	_, err = tx.Exec(`UPDATE event_comments SET body = '', body_html = '', pinned_at = NULL, deleted_at = ?
+	WHERE user_id = ? AND deleted_at IS NULL`, now, userID)
+	if err != nil {
+		return err
+	}
+
+	// the ratings still count, the reviews may tell who wrote them
+	_, err = tx.Exec(`UPDATE event_feedback SET review = '' WHERE user_id = ?`, userID)
+	if err != nil {
+		return err
+	}
+
+	for _, query := range []string{
+		`DELETE FROM event_access WHERE user_id = ?`,
+		`DELETE FROM notifications WHERE user_id = ?`,
+		`DELETE FROM org_members WHERE user_id = ?`,
+		`DELETE FROM api_keys WHERE user_id = ?`,
+		`DELETE FROM user_identities WHERE user_id = ?`,
+		`DELETE FROM idempotency_keys WHERE user_id = ?`,
+		`DELETE FROM user_totp WHERE user_id = ?`,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = ?`,
+		`DELETE FROM mfa_challenges WHERE user_id = ?`,
+		`DELETE FROM sessions WHERE user_id = ?`,
+	} {
+		_, err = tx.Exec(query, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// the row stays for the past events and registrations, but nothing points to the person anymore
+	_, err = tx.Exec(`
+	UPDATE users SET email = ?, password = '', display_name = '', timezone = 'UTC',
+		notify_confirmations = 0, notify_reminders = 0, is_admin = 0, deleted_at = ?
+	WHERE id = ?`, fmt.Sprintf("deleted-user-%d@deleted.invalid", userID), now, userID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	removeBlobs(blobKeys)
+	invalidateUserSessions(userID)
+	for _, change := range changed {
+		invalidateEvent(change.Event.ID)
+		publishEventChange(change.Kind, change.Event)
+	}
+
+	return nil
+}
@@ -0,0 +1,195 @@
+package models
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"events-booking/db"
+	"slices"
+	"strings"
+	"time"
+)
+
+// API key scopes, a key can only call the routes of its scopes
+const (
+	ScopeEventsRead         = "events:read"
+	ScopeEventsWrite        = "events:write"
+	ScopeRegistrationsRead  = "registrations:read"
+	ScopeRegistrationsWrite = "registrations:write"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("api key is invalid or expired")
+)
+
+// keys look like eb_<prefix>_<secret>
+const apiKeyTag = "eb_"
+
+// last_used_at is written at most this often per key
+const apiKeyUsedInterval = time.Minute
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	Name       string     `json:"name" binding:"required,max=100"`
+	Prefix     string     `json:"prefix"` // shown in listings so the user can tell the keys apart
+	Scopes     []string   `json:"scopes" binding:"required,min=1,dive,oneof=events:read events:write registrations:read registrations:write"`
+	ExpiresAt  *time.Time `json:"expires_at"` // nil never expires
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Save creates the key and returns it in plain text, the only time it is available.
+func (k *APIKey) Save() (string, error) {
+	prefix, err := randomString(6)
+	if err != nil {
+		return "", err
+	}
+	secret, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	k.Prefix = prefix
+	k.CreatedAt = time.Now().UTC()
+	if k.ExpiresAt != nil {
+		expiresAt := k.ExpiresAt.UTC()
+		k.ExpiresAt = &expiresAt
+	}
+
+	result, err := db.DB.Exec(`
+	INSERT INTO api_keys (user_id, name, prefix, key_hash, scopes, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		k.UserID, k.Name, k.Prefix, hashSecret(secret), strings.Join(k.Scopes, " "), k.ExpiresAt, k.CreatedAt)
+	if err != nil {
+		return "", err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+	k.ID = id
+
+	return apiKeyTag + prefix + "_" + secret, nil
+}
+
+func (k APIKey) HasScope(scope string) bool {
+	return slices.Contains(k.Scopes, scope)
+}
+
+// AuthenticateAPIKey returns the key for the plain text key sent by a client
+// and records that it was used. Unknown, wrong and expired keys give ErrInvalidAPIKey.
+func AuthenticateAPIKey(plain string) (*APIKey, error) {
+	prefix, secret, ok := strings.Cut(strings.TrimPrefix(plain, apiKeyTag), "_")
+	if !ok || !strings.HasPrefix(plain, apiKeyTag) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	var keyHash string
+	key, err := scanAPIKey(db.DB.QueryRow(`SELECT `+apiKeyColumns+`, key_hash FROM api_keys WHERE prefix = ?`, prefix), &keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if subtle.ConstantTimeCompare([]byte(keyHash), []byte(hashSecret(secret))) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now().UTC()
+	if key.ExpiresAt != nil && !now.Before(*key.ExpiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	_, err = db.DB.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ? AND (last_used_at IS NULL OR last_used_at < ?)`,
+		now, key.ID, now.Add(-apiKeyUsedInterval))
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func GetUserAPIKeys(userID int64) ([]APIKey, error) {
+	rows, err := db.DB.Query(`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, *key)
+	}
+
+	return keys, rows.Err()
+}
+
+// DeleteAPIKey revokes one of the user's keys right away.
+func DeleteAPIKey(userID int64, id int64) error {
+	result, err := db.DB.Exec(`DELETE FROM api_keys WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAPIKeyNotFound
+	}
+
+	return nil
+}
+
+const apiKeyColumns = "id, user_id, name, prefix, scopes, expires_at, last_used_at, created_at"
+
+func scanAPIKey(row scanner, extra ...any) (*APIKey, error) {
+	var key APIKey
+	var scopes string
+	var expiresAt, lastUsedAt sql.NullTime
+
+	dest := append([]any{&key.ID, &key.UserID, &key.Name, &key.Prefix, &scopes, &expiresAt, &lastUsedAt, &key.CreatedAt}, extra...)
+	err := row.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	key.Scopes = strings.Fields(scopes)
+	if expiresAt.Valid {
+		key.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		key.LastUsedAt = &lastUsedAt.Time
+	}
+
+	return &key, nil
+}
+
+// hashSecret is for random secrets like API keys and recovery codes,
+// a fast hash is enough for them (no bcrypt on every request)
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomString(bytes int) (string, error) {
+	b := make([]byte, bytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,296 @@
+package models
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"events-booking/blob"
+	"events-booking/db"
+	"events-booking/utils"
+	"fmt"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gabriel-vasile/mimetype"
+)
+
+var (
+	ErrAttachmentNotFound  = errors.New("attachment not found")
+	ErrUnsupportedFileType = errors.New("unsupported file type")
+	ErrTooManyAttachments  = errors.New("the event has too many attachments")
+)
+
+// the types accepted, as sniffed from the content; what the client claims is ignored
+var attachmentTypes = []string{"image/jpeg", "image/png", "image/gif", "application/pdf"}
+
+const (
+	maxAttachmentsPerEvent = 20
+	thumbnailSize          = 320 // pixels, the longer side
+)
+
+// blobStore holds the attachment files, set once at startup by SetBlobStore.
+var blobStore blob.BlobStore
+
+func SetBlobStore(store blob.BlobStore) {
+	blobStore = store
+}
+
+type Attachment struct {
+	ID           int64     `json:"id"`
+	EventID      int64     `json:"event_id"`
+	Filename     string    `json:"filename"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	SHA256       string    `json:"sha256"`
+	HasThumbnail bool      `json:"has_thumbnail"`
+	UploadedBy   int64     `json:"uploaded_by"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	blobKey      string
+	thumbnailKey string
+}
+
+const attachmentColumns = `id, event_id, filename, content_type, size, sha256, blob_key, thumbnail_key, uploaded_by, created_at`
+
+// SaveAttachment stores the file of an upload, the size limit is up to the caller.
+// Images get a JPEG thumbnail.
+func SaveAttachment(ctx context.Context, eventID int64, userID int64, filename string, data []byte) (*Attachment, error) {
+	if blobStore == nil {
+		return nil, errors.New("no blob store configured")
+	}
+
+	contentType := ""
+	detected := mimetype.Detect(data)
+	for _, allowed := range attachmentTypes {
+		if detected.Is(allowed) {
+			contentType = allowed
+			break
+		}
+	}
+	if contentType == "" {
+		return nil, fmt.Errorf("%w: %s, use JPEG, PNG, GIF or PDF", ErrUnsupportedFileType, detected.String())
+	}
+
+	var count int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM event_attachments WHERE event_id = ?`, eventID).Scan(&count)
+	if err != nil {
+		return nil, err
+	}
+	if count >= maxAttachmentsPerEvent {
+		return nil, ErrTooManyAttachments
+	}
+
+	var thumbnail []byte
+	if strings.HasPrefix(contentType, "image/") {
+		thumbnail, _, _, err = utils.Thumbnail(data, thumbnailSize)
+		if err != nil {
+			return nil, fmt.Errorf("%w: could not read the image: %v", ErrUnsupportedFileType, err)
+		}
+	}
+
+	random, err := randomString(16)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(data)
+	a := Attachment{
+		EventID:      eventID,
+		Filename:     cleanFilename(filename, detected.Extension()),
+		ContentType:  contentType,
+		Size:         int64(len(data)),
+		SHA256:       hex.EncodeToString(sum[:]),
+		HasThumbnail: thumbnail != nil,
+		UploadedBy:   userID,
+		CreatedAt:    time.Now().UTC(),
+		blobKey:      fmt.Sprintf("events/%d/%s%s", eventID, random, detected.Extension()),
+	}
+
+	err = blobStore.Put(ctx, a.blobKey, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	keys := []string{a.blobKey}
+
+	if thumbnail != nil {
+		a.thumbnailKey = fmt.Sprintf("events/%d/%s-thumb.jpg", eventID, random)
+		err = blobStore.Put(ctx, a.thumbnailKey, bytes.NewReader(thumbnail))
+		if err != nil {
+			removeBlobs(keys)
+			return nil, err
+		}
+		keys = append(keys, a.thumbnailKey)
+	}
+
+	result, err := db.DB.Exec(`
+	INSERT INTO event_attachments (event_id, filename, content_type, size, sha256, blob_key, thumbnail_key, uploaded_by, created_at)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		a.EventID, a.Filename, a.ContentType, a.Size, a.SHA256, a.blobKey, sql.NullString{String: a.thumbnailKey, Valid: thumbnail != nil},
+		a.UploadedBy, a.CreatedAt)
+	if err != nil {
+		removeBlobs(keys)
+		return nil, err
+	}
+
+	a.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return &a, nil
+}
+
+func GetEventAttachments(eventID int64) ([]Attachment, error) {
+	rows, err := db.DB.Query(`SELECT `+attachmentColumns+` FROM event_attachments WHERE event_id = ? ORDER BY id`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		attachments = append(attachments, *a)
+	}
+
+	return attachments, rows.Err()
+}
+
+func GetAttachment(eventID int64, id int64) (*Attachment, error) {
+	row := db.DB.QueryRow(`SELECT `+attachmentColumns+` FROM event_attachments WHERE event_id = ? AND id = ?`, eventID, id)
+
+	a, err := scanAttachment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAttachmentNotFound
+	}
+	return a, err
+}
+
+// Open reads the file, or its thumbnail. Attachments without one return ErrAttachmentNotFound.
+func (a Attachment) Open(ctx context.Context, thumbnail bool) (io.ReadSeekCloser, error) {
+	if blobStore == nil {
+		return nil, errors.New("no blob store configured")
+	}
+
+	key := a.blobKey
+	if thumbnail {
+		key = a.thumbnailKey
+	}
+	if key == "" {
+		return nil, ErrAttachmentNotFound
+	}
+
+	file, err := blobStore.Open(ctx, key)
+	if errors.Is(err, blob.ErrNotFound) {
+		return nil, ErrAttachmentNotFound
+	}
+	return file, err
+}
+
+func (a Attachment) Delete() error {
+	result, err := db.DB.Exec(`DELETE FROM event_attachments WHERE id = ?`, a.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAttachmentNotFound
+	}
+
+	removeBlobs([]string{a.blobKey, a.thumbnailKey})
+	return nil
+}
+
+// deleteEventAttachments removes the rows of a deleted event and returns the
+// blob keys, to be removed with removeBlobs once the transaction is committed.
+func deleteEventAttachments(tx *sql.Tx, eventID int64) ([]string, error) {
+	rows, err := tx.Query(`DELETE FROM event_attachments WHERE event_id = ? RETURNING blob_key, thumbnail_key`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+	for rows.Next() {
+		var blobKey string
+		var thumbnailKey sql.NullString
+		err := rows.Scan(&blobKey, &thumbnailKey)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, blobKey, thumbnailKey.String)
+	}
+
+	return keys, rows.Err()
+}
+
+// removeBlobs deletes files nothing points to anymore. A failure only leaves
+// an orphaned file behind, so it is logged instead of failing the request.
+func removeBlobs(keys []string) {
+	if blobStore == nil {
+		return
+	}
+
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		err := blobStore.Delete(context.Background(), key)
+		if err != nil {
+			log.Printf("Could not delete blob %s: %v", key, err)
+		}
+	}
+}
+
+func scanAttachment(row scanner) (*Attachment, error) {
+	var a Attachment
+	var thumbnailKey sql.NullString
+
+	err := row.Scan(&a.ID, &a.EventID, &a.Filename, &a.ContentType, &a.Size, &a.SHA256,
+		&a.blobKey, &thumbnailKey, &a.UploadedBy, &a.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	a.thumbnailKey = thumbnailKey.String
+	a.HasThumbnail = thumbnailKey.Valid
+	return &a, nil
+}
+
+// cleanFilename keeps the base name of the upload without control characters,
+// it ends up in a Content-Disposition header.
+func cleanFilename(filename string, extension string) string {
+	filename = filepath.Base(strings.ReplaceAll(filename, `\`, "/"))
+	filename = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, filename)
+
+	if filename == "" || filename == "." || filename == "/" {
+		filename = "attachment" + extension
+	}
+	// cut from the front, the extension matters more
+	for len(filename) > 200 {
+		_, size := utf8.DecodeRuneInString(filename)
+		filename = filename[size:]
+	}
+
+	return filename
+}
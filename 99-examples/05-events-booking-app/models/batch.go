@@ -0,0 +1,183 @@
+package models
+
+import (
+	"events-booking/db"
+	"strings"
+)
+
+// The lookups below load many rows with one query, keyed by the requested IDs,
+// so callers resolving relations (the GraphQL dataloaders) avoid one query per row.
+
+// inList returns the "(?, ?, ...)" placeholders and the args for the IDs, which must not be empty.
+func inList(ids []int64) (string, []any) {
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		args[i] = id
+	}
+
+	return "(?" + strings.Repeat(", ?", len(ids)-1) + ")", args
+}
+
+// GetUsersByIDs loads the users without their password hashes. Unknown IDs are left out.
+func GetUsersByIDs(ids []int64) (map[int64]User, error) {
+	users := map[int64]User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	in, args := inList(ids)
+	rows, err := db.DB.Query("SELECT id, email FROM users WHERE id IN "+in, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var u User
+		err := rows.Scan(&u.Id, &u.Email)
+		if err != nil {
+			return nil, err
+		}
+		users[u.Id] = u
+	}
+
+	return users, nil
+}
+
+// GetOrgEventsByIDs loads the events with their tags, leaving out the ones
+// outside the organization like GetOrgEventByID does.
+func GetOrgEventsByIDs(orgID *int64, ids []int64) (map[int64]Event, error) {
+	found := map[int64]Event{}
+	if len(ids) == 0 {
+		return found, nil
+	}
+
+	in, args := inList(ids)
+	list, err := queryEvents("org_id IS ? AND id IN "+in, append([]any{orgID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range list {
+		found[e.ID] = e
+	}
+
+	return found, nil
+}
+
+// GetOrgEventsByOwners groups the organization's events by their owner.
+func GetOrgEventsByOwners(orgID *int64, ownerIDs []int64) (map[int64][]Event, error) {
+	owned := map[int64][]Event{}
+	if len(ownerIDs) == 0 {
+		return owned, nil
+	}
+
+	in, args := inList(ownerIDs)
+	list, err := queryEvents("org_id IS ? AND user_id IN "+in+" ORDER BY date", append([]any{orgID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range list {
+		owned[e.UserID] = append(owned[e.UserID], e)
+	}
+
+	return owned, nil
+}
+
+func queryEvents(where string, args ...any) ([]Event, error) {
+	rows, err := db.DB.Query("SELECT "+eventColumns+" FROM events WHERE "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var list []Event
+
+	for rows.Next() {
+		var e Event
+		err := scanEvent(rows, &e)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, e)
+	}
+
+	err = attachTags(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+// GetRegistrationsByEvents groups the registrations by event.
+func GetRegistrationsByEvents(eventIDs []int64) (map[int64][]Registration, error) {
+	return groupRegistrations("event_id", eventIDs)
+}
+
+// GetRegistrationsByUsers groups the registrations by user.
+func GetRegistrationsByUsers(userIDs []int64) (map[int64][]Registration, error) {
+	return groupRegistrations("user_id", userIDs)
+}
+
+func groupRegistrations(column string, ids []int64) (map[int64][]Registration, error) {
+	grouped := map[int64][]Registration{}
+	if len(ids) == 0 {
+		return grouped, nil
+	}
+
+	in, args := inList(ids)
+	query := "SELECT id, user_id, event_id, created_at, status FROM registrations WHERE " + column + " IN " + in + " ORDER BY created_at, id"
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r Registration
+		err := rows.Scan(&r.ID, &r.UserID, &r.EventID, &r.CreatedAt, &r.Status)
+		if err != nil {
+			return nil, err
+		}
+
+		key := r.EventID
+		if column == "user_id" {
+			key = r.UserID
+		}
+		grouped[key] = append(grouped[key], r)
+	}
+
+	return grouped, nil
+}
+
+// GetHostRoles returns the user's accepted host role per event, events without one are left out.
+func GetHostRoles(userID int64, eventIDs []int64) (map[int64]string, error) {
+	roles := map[int64]string{}
+	if len(eventIDs) == 0 {
+		return roles, nil
+	}
+
+	in, args := inList(eventIDs)
+	query := "SELECT event_id, role FROM event_hosts WHERE user_id = ? AND status = ? AND event_id IN " + in
+
+	rows, err := db.DB.Query(query, append([]any{userID, HostAccepted}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventID int64
+		var role string
+		err := rows.Scan(&eventID, &role)
+		if err != nil {
+			return nil, err
+		}
+		roles[eventID] = role
+	}
+
+	return roles, nil
+}
@@ -0,0 +1,159 @@
+package models
+
+import (
+	"events-booking/cache"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// eventCache holds event listings and details, nil when caching is off.
+// Every write to events drops the keys it affects.
+var eventCache *cache.ReadThrough
+
+// cache keys, lists are grouped under one prefix so a write can drop them all
+const (
+	eventKeyPrefix     = "event:"
+	eventListKeyPrefix = "events:list:"
+)
+
+// SetEventCache turns on caching of GetEvents and the GetEventByID lookups.
+// Call it before serving requests.
+func SetEventCache(c cache.Cache) {
+	eventCache = cache.NewReadThrough(c)
+}
+
+// EventCacheStats reports false when caching is off.
+func EventCacheStats() (cache.Stats, bool) {
+	if eventCache == nil {
+		return cache.Stats{}, false
+	}
+	return eventCache.Stats(), true
+}
+
+func eventKey(id int64) string {
+	return eventKeyPrefix + strconv.FormatInt(id, 10)
+}
+
+func eventListKey(filter EventFilter) string {
+	org := "-"
+	if filter.OrgID != nil {
+		org = strconv.FormatInt(*filter.OrgID, 10)
+	}
+
+	tags := normalizeTags(filter.Tags)
+	slices.Sort(tags)
+
+	return eventListKeyPrefix + org + ":" + filter.Category + ":" + strings.Join(tags, ",") +
+		":" + timeKey(filter.From) + ":" + timeKey(filter.To)
+}
+
+func timeKey(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return strconv.FormatInt(t.UnixNano(), 10)
+}
+
+// invalidateEvent drops the event and every listing, any of them may contain it.
+func invalidateEvent(id int64) {
+	if eventCache == nil {
+		return
+	}
+	eventCache.Invalidate(eventKey(id))
+	eventCache.InvalidatePrefix(eventListKeyPrefix)
+}
+
+// invalidateEventDetail is for writes next to the event, like registrations,
+// that can't move it between listings.
+func invalidateEventDetail(id int64) {
+	if eventCache == nil {
+		return
+	}
+	eventCache.Invalidate(eventKey(id))
+}
+
+func invalidateEventLists() {
+	if eventCache == nil {
+		return
+	}
+	eventCache.InvalidatePrefix(eventListKeyPrefix)
+}
+
+// invalidateAllEvents is for writes touching many events at once.
+func invalidateAllEvents() {
+	if eventCache == nil {
+		return
+	}
+	eventCache.InvalidatePrefix(eventKeyPrefix)
+	eventCache.InvalidatePrefix(eventListKeyPrefix)
+}
+
+// cachedEvents returns a copy of the cached listing so callers can't change the cached one.
+func cachedEvents(key string, load func() ([]Event, error)) ([]Event, error) {
+	if eventCache == nil {
+		return load()
+	}
+
+	value, err := eventCache.Get(key, func() (any, error) {
+		return load()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := value.([]Event)
+	if events == nil {
+		return nil, nil
+	}
+
+	copies := make([]Event, len(events))
+	for i, e := range events {
+		copies[i] = e.clone()
+	}
+
+	return copies, nil
+}
+
+func cachedEvent(id int64, load func() (*Event, error)) (*Event, error) {
+	if eventCache == nil {
+		return load()
+	}
+
+	value, err := eventCache.Get(eventKey(id), func() (any, error) {
+		return load()
+	})
+	if err != nil {
+		return &Event{}, err
+	}
+
+	event := value.(*Event).clone()
+	return &event, nil
+}
+
+func sameOrg(a *int64, b *int64) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+// clone copies what the Event shares through pointers and slices.
+func (e Event) clone() Event {
+	e.Tags = slices.Clone(e.Tags)
+	if e.CategoryID != nil {
+		categoryID := *e.CategoryID
+		e.CategoryID = &categoryID
+	}
+	if e.OrgID != nil {
+		orgID := *e.OrgID
+		e.OrgID = &orgID
+	}
+	e.EndsAt = utcTime(e.EndsAt)
+	if e.Latitude != nil && e.Longitude != nil {
+		latitude, longitude := *e.Latitude, *e.Longitude
+		e.Latitude, e.Longitude = &latitude, &longitude
+	}
+	return e
+}
@@ -0,0 +1,187 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"log"
+)
+
+var (
+	ErrUnknownCategory  = errors.New("category does not exist")
+	ErrCategoryNotFound = errors.New("category not found")
+)
+
+type Category struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name" binding:"required,max=50"`
+	Slug string `json:"slug" binding:"required,max=50"`
+}
+
+func (c *Category) Save() error {
+	result, err := db.DB.Exec(`INSERT INTO categories (name, slug) VALUES (?, ?)`, c.Name, c.Slug)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+	c.ID = id
+
+	return nil
+}
+
+// Update renames the category. Its events show it, so they get a new version.
+func (c Category) Update() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE categories SET name = ?, slug = ? WHERE id = ?`, c.Name, c.Slug, c.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	ids, err := bumpCategoryEvents(tx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	invalidateAllEvents()
+	publishUpdatedEvents(ids)
+
+	return nil
+}
+
+// Delete removes the category, its events stay but lose their category.
+func (c Category) Delete() error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	ids, err := bumpCategoryEvents(tx, c.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE events SET category_id = NULL WHERE category_id = ?`, c.ID)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`DELETE FROM categories WHERE id = ?`, c.ID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCategoryNotFound
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	invalidateAllEvents()
+	publishUpdatedEvents(ids)
+
+	return nil
+}
+
+// bumpCategoryEvents gives the category's events a new version, so ETags and
+// version checks see the change, and returns their IDs.
+func bumpCategoryEvents(tx *sql.Tx, categoryID int64) ([]int64, error) {
+	rows, err := tx.Query(`UPDATE events SET version = version + 1 WHERE category_id = ? RETURNING id`, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int64
+	for rows.Next() {
+		var id int64
+		err := rows.Scan(&id)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// publishUpdatedEvents tells the watchers about events changed by a write next
+// to them. The write is committed already, so a failed lookup is only logged.
+func publishUpdatedEvents(ids []int64) {
+	if len(ids) == 0 {
+		return
+	}
+
+	in, args := inList(ids)
+	list, err := queryEvents("id IN "+in, args...)
+	if err != nil {
+		log.Printf("Could not publish the updated events %v: %v", ids, err)
+		return
+	}
+	for _, e := range list {
+		publishEventChange(EventUpdated, e)
+	}
+}
+
+func GetAllCategories() ([]Category, error) {
+	rows, err := db.DB.Query(`SELECT id, name, slug FROM categories ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	categories := []Category{}
+
+	for rows.Next() {
+		var c Category
+		err := rows.Scan(&c.ID, &c.Name, &c.Slug)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, c)
+	}
+
+	return categories, nil
+}
+
+// checkCategory makes sure an event does not point at a missing category.
+func checkCategory(categoryID *int64) error {
+	if categoryID == nil {
+		return nil
+	}
+
+	var id int64
+	err := db.DB.QueryRow(`SELECT id FROM categories WHERE id = ?`, *categoryID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrUnknownCategory
+	}
+
+	return err
+}
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestCategoryChangesBumpEventVersions(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(c Category) error
+	}{
+		{"rename", func(c Category) error {
+			c.Name = "Renamed"
+			return c.Update()
+		}},
+		{"delete", Category.Delete},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slug := fmt.Sprintf("%s-%d", tt.name, time.Now().UnixNano())
+			c := Category{Name: "Talks", Slug: slug}
+			err := c.Save()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			e := newTestEvent(t, false)
+			e.CategoryID = &c.ID
+			err = e.Update()
+			if err != nil {
+				t.Fatal(err)
+			}
+			before, err := GetEventByID(e.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = tt.change(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			after, err := GetEventByID(e.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if after.Version <= before.Version {
+				t.Errorf("version %d after the change, want more than %d", after.Version, before.Version)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"sync"
+)
+
+// Kinds of event changes
+const (
+	EventCreated = "created"
+	EventUpdated = "updated"
+	EventDeleted = "deleted"
+)
+
+// EventChange is published after an event write committed, whichever API made it.
+type EventChange struct {
+	Kind  string
+	Event Event // for deletes, the event as it was
+	// for updates that changed the visibility, the one before, "" otherwise
+	PreviousVisibility string
+}
+
+// how many changes a subscriber may fall behind before it is dropped
+const changeBuffer = 64
+
+var changes = struct {
+	sync.Mutex
+	subscribers map[chan EventChange]struct{}
+}{subscribers: map[chan EventChange]struct{}{}}
+
+// SubscribeEventChanges returns the changes made in this process from now on.
+// A subscriber that doesn't keep up has its channel closed and should resync.
+// cancel must be called once the changes are not needed anymore.
+func SubscribeEventChanges() (updates <-chan EventChange, cancel func()) {
+	ch := make(chan EventChange, changeBuffer)
+
+	changes.Lock()
+	changes.subscribers[ch] = struct{}{}
+	changes.Unlock()
+
+	return ch, func() {
+		changes.Lock()
+		defer changes.Unlock()
+
+		_, ok := changes.subscribers[ch]
+		if ok {
+			delete(changes.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+func publishEventChange(kind string, e Event) {
+	publish(EventChange{Kind: kind, Event: e})
+}
+
+func publish(change EventChange) {
+	changes.Lock()
+	defer changes.Unlock()
+
+	for ch := range changes.subscribers {
+		select {
+		case ch <- change:
+		default:
+			// never block the writer on a slow subscriber
+			delete(changes.subscribers, ch)
+			close(ch)
+		}
+	}
+}
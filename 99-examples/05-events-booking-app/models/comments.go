@@ -0,0 +1,293 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"events-booking/utils"
+	"strings"
+	"time"
+)
+
+var (
+	ErrCommentNotFound = errors.New("comment not found")
+	ErrCannotPinReply  = errors.New("only the first comment of a thread can be pinned")
+)
+
+// Comment is a question or answer on an event. Replies point to the first
+// comment of their thread, threads are one level deep.
+type Comment struct {
+	ID         int64      `json:"id"`
+	EventID    int64      `json:"event_id"`
+	ParentID   *int64     `json:"parent_id"` // nil for the first comment of a thread
+	UserID     *int64     `json:"user_id"`   // nil once deleted
+	AuthorName string     `json:"author_name"`
+	Body       string     `json:"body"`      // the markdown as written
+	BodyHTML   string     `json:"body_html"` // sanitized, safe to show as is
+	Pinned     bool       `json:"pinned"`
+	Hidden     bool       `json:"hidden"`  // only hosts see hidden comments
+	Deleted    bool       `json:"deleted"` // kept as a placeholder while it has replies
+	ReplyCount int        `json:"reply_count"`
+	CreatedAt  time.Time  `json:"created_at"`
+	EditedAt   *time.Time `json:"edited_at"`
+}
+
+// withHidden is bound twice, for the comment and for counting its replies
+const commentColumns = `c.id, c.event_id, c.parent_id, c.user_id, u.display_name, c.body, c.body_html,
+	c.pinned_at IS NOT NULL, c.hidden_at IS NOT NULL, c.deleted_at IS NOT NULL, c.created_at, c.edited_at,
+	(SELECT COUNT(*) FROM event_comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL AND (? OR r.hidden_at IS NULL)) AS replies`
+
+const commentFrom = ` FROM event_comments c JOIN users u ON u.id = c.user_id `
+
+// CanComment reports whether the user may write on the event: its hosts and
+// users with a confirmed registration, attended or not.
+func CanComment(eventID int64, userID int64) (bool, error) {
+	var allowed bool
+	err := db.DB.QueryRow(`
+	SELECT EXISTS (SELECT 1 FROM event_hosts WHERE event_id = ? AND user_id = ? AND status = ?)
+		OR EXISTS (SELECT 1 FROM registrations WHERE event_id = ? AND user_id = ? AND status IN (?, ?, ?))`,
+		eventID, userID, HostAccepted,
+		eventID, userID, RegistrationConfirmed, RegistrationAttended, RegistrationNoShow).Scan(&allowed)
+
+	return allowed, err
+}
+
+// CreateComment starts a thread, or replies to one when parentID is set. A reply
+// to a reply joins the same thread.
+func CreateComment(eventID int64, userID int64, parentID *int64, body string) (*Comment, error) {
+	if parentID != nil {
+		parent, err := GetComment(eventID, *parentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.Hidden {
+			return nil, ErrCommentNotFound
+		}
+		if parent.ParentID != nil {
+			parentID = parent.ParentID
+		}
+	}
+
+	now := time.Now().UTC()
+	body = strings.TrimSpace(body)
+	result, err := db.DB.Exec(`
+	INSERT INTO event_comments (event_id, parent_id, user_id, body, body_html, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`,
+		eventID, parentID, userID, body, utils.RenderMarkdown(body), now)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+
+	return GetComment(eventID, id)
+}
+
+// GetComment returns ErrCommentNotFound for deleted comments, hidden ones are
+// returned with Hidden set.
+func GetComment(eventID int64, id int64) (*Comment, error) {
+	return getComment(`WHERE c.event_id = ? AND c.id = ? AND c.deleted_at IS NULL`, eventID, id)
+}
+
+// GetThread returns the first comment of a thread, deleted or not, so the
+// replies of a deleted question can still be listed.
+func GetThread(eventID int64, id int64) (*Comment, error) {
+	return getComment(`WHERE c.event_id = ? AND c.id = ? AND c.parent_id IS NULL`, eventID, id)
+}
+
+func getComment(where string, args ...any) (*Comment, error) {
+	row := db.DB.QueryRow(`SELECT `+commentColumns+commentFrom+where, append([]any{true}, args...)...)
+
+	c, err := scanComment(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCommentNotFound
+	}
+	return c, err
+}
+
+// GetComments pages through the threads of an event, newest first, or through
+// the replies of a thread, oldest first. after is the ID of the last comment of
+// the previous page, 0 for the first page. The returned ID is the one to pass
+// for the next page, 0 when there is none. Pinned threads are left out, see
+// GetPinnedComments. Hidden comments are only included withHidden.
+func GetComments(eventID int64, parentID *int64, after int64, limit int, withHidden bool) ([]Comment, int64, error) {
+	var query string
+	var args []any
+	if parentID == nil {
+		// a deleted thread stays as long as it has replies
+		query = `SELECT ` + commentColumns + commentFrom + `
+		WHERE c.event_id = ? AND c.parent_id IS NULL AND c.pinned_at IS NULL AND (? OR c.hidden_at IS NULL)
+			AND (c.deleted_at IS NULL OR replies > 0) AND (? = 0 OR c.id < ?)
+		ORDER BY c.id DESC LIMIT ?`
+		args = []any{withHidden, eventID, withHidden, after, after, limit + 1}
+	} else {
+		query = `SELECT ` + commentColumns + commentFrom + `
+		WHERE c.event_id = ? AND c.parent_id = ? AND (? OR c.hidden_at IS NULL) AND c.deleted_at IS NULL AND c.id > ?
+		ORDER BY c.id LIMIT ?`
+		args = []any{withHidden, eventID, *parentID, withHidden, after, limit + 1}
+	}
+
+	comments, err := queryComments(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	// one more than asked for tells whether there is a next page
+	var next int64
+	if len(comments) > limit {
+		comments = comments[:limit]
+		next = comments[limit-1].ID
+	}
+
+	return comments, next, nil
+}
+
+// GetPinnedComments lists the pinned threads, the most recently pinned first.
+func GetPinnedComments(eventID int64, withHidden bool) ([]Comment, error) {
+	return queryComments(`SELECT `+commentColumns+commentFrom+`
+	WHERE c.event_id = ? AND c.parent_id IS NULL AND c.pinned_at IS NOT NULL AND (? OR c.hidden_at IS NULL)
+		AND (c.deleted_at IS NULL OR replies > 0)
+	ORDER BY c.pinned_at DESC`, withHidden, eventID, withHidden)
+}
+
+func (c *Comment) Edit(body string) error {
+	now := time.Now().UTC()
+	body = strings.TrimSpace(body)
+	html := utils.RenderMarkdown(body)
+
+	result, err := db.DB.Exec(`UPDATE event_comments SET body = ?, body_html = ?, edited_at = ? WHERE id = ? AND deleted_at IS NULL`,
+		body, html, now, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = checkCommentChanged(result)
+	if err != nil {
+		return err
+	}
+
+	c.Body, c.BodyHTML, c.EditedAt = body, html, &now
+	return nil
+}
+
+// Delete clears the text right away, the row stays so the replies keep their thread.
+func (c Comment) Delete() error {
+	result, err := db.DB.Exec(`UPDATE event_comments SET body = '', body_html = '', pinned_at = NULL, deleted_at = ?
+	WHERE id = ? AND deleted_at IS NULL`, time.Now().UTC(), c.ID)
+	if err != nil {
+		return err
+	}
+
+	return checkCommentChanged(result)
+}
+
+// SetHidden hides the comment from everyone but the hosts, or shows it again.
+func (c *Comment) SetHidden(hidden bool, by int64) error {
+	hiddenAt, hiddenBy := any(nil), any(nil)
+	if hidden {
+		hiddenAt, hiddenBy = time.Now().UTC(), by
+	}
+
+	result, err := db.DB.Exec(`UPDATE event_comments SET hidden_at = ?, hidden_by = ? WHERE id = ? AND deleted_at IS NULL`,
+		hiddenAt, hiddenBy, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = checkCommentChanged(result)
+	if err != nil {
+		return err
+	}
+
+	c.Hidden = hidden
+	return nil
+}
+
+// SetPinned moves the thread to the top of the event's comments, or back.
+func (c *Comment) SetPinned(pinned bool) error {
+	if c.ParentID != nil {
+		return ErrCannotPinReply
+	}
+
+	pinnedAt := any(nil)
+	if pinned {
+		pinnedAt = time.Now().UTC()
+	}
+
+	result, err := db.DB.Exec(`UPDATE event_comments SET pinned_at = ? WHERE id = ? AND deleted_at IS NULL`, pinnedAt, c.ID)
+	if err != nil {
+		return err
+	}
+
+	err = checkCommentChanged(result)
+	if err != nil {
+		return err
+	}
+
+	c.Pinned = pinned
+	return nil
+}
+
+func checkCommentChanged(result sql.Result) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrCommentNotFound
+	}
+
+	return nil
+}
+
+func queryComments(query string, args ...any) ([]Comment, error) {
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	comments := []Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(comments, *c)
+	}
+
+	return comments, rows.Err()
+}
+
+func scanComment(row scanner) (*Comment, error) {
+	var c Comment
+	var userID int64
+
+	err := row.Scan(&c.ID, &c.EventID, &c.ParentID, &userID, &c.AuthorName, &c.Body, &c.BodyHTML,
+		&c.Pinned, &c.Hidden, &c.Deleted, &c.CreatedAt, &c.EditedAt, &c.ReplyCount)
+	if err != nil {
+		return nil, err
+	}
+
+	// what is left of a deleted comment doesn't point to its author
+	if c.Deleted {
+		c.AuthorName = ""
+	} else {
+		c.UserID = &userID
+	}
+	return &c, nil
+}
+
+// deleteEventComments removes the comments of a deleted event, replies first.
+func deleteEventComments(tx *sql.Tx, eventID int64) error {
+	_, err := tx.Exec(`DELETE FROM event_comments WHERE event_id = ? AND parent_id IS NOT NULL`, eventID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM event_comments WHERE event_id = ?`, eventID)
+	return err
+}
@@ -1,33 +1,100 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
 	"events-booking/db"
+	"fmt"
+	"strings"
 	"time"
 )
 
+// ErrVersionConflict is returned when the event was changed by someone else
+// after the caller read it.
+var ErrVersionConflict = errors.New("event was modified by another request")
+
+var ErrInvalidCoordinates = errors.New("invalid coordinates")
+
+var ErrInvalidEndTime = errors.New("the event must end after it starts")
+
+// DefaultEventDuration is how long events without an end time are taken to last.
+const DefaultEventDuration = 2 * time.Hour
+
 type Event struct {
 	ID          int64     `json:"id"`
 	Name        string    `json:"name" binding:"required"`
 	Description string    `json:"description" binding:"required"`
 	DateTime    time.Time `json:"date" binding:"required"`
-	Location    string    `json:"location" binding:"required"`
-	UserID      int64     `json:"user_id"`
+	// optional, see EndTime
+	EndsAt     *time.Time `json:"ends_at" binding:"omitempty,gtfield=DateTime"`
+	Location   string     `json:"location" binding:"required"`
+	UserID     int64      `json:"user_id"`
+	Version    int64      `json:"version"`
+	CategoryID *int64     `json:"category_id"`
+	Tags       []string   `json:"tags" binding:"omitempty,max=10,dive,min=1,max=30"`
+	OrgID      *int64     `json:"org_id"` // nil for events outside any organization
+	// both or neither are set, in degrees (WGS 84)
+	Latitude  *float64 `json:"latitude" binding:"omitempty,required_with=Longitude,min=-90,max=90"`
+	Longitude *float64 `json:"longitude" binding:"omitempty,required_with=Latitude,min=-180,max=180"`
+	// registrations start pending until the owner approves them
+	RequiresApproval bool `json:"requires_approval"`
+	// empty keeps the current visibility on update, new events are public
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+}
+
+// EventFilter narrows down event listings, empty fields don't filter.
+// OrgID always applies: nil only lists the events outside any organization.
+// Listings only ever show public events.
+type EventFilter struct {
+	OrgID    *int64
+	Category string     // category slug
+	Tags     []string   // the event needs every one of these tags
+	From     *time.Time // events on or after
+	To       *time.Time // events before
 }
 
 func (e *Event) Save() error {
 	query := `
-		INSERT INTO events (name, description, date, location, user_id)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO events (name, description, date, ends_at, location, user_id, category_id, org_id, latitude, longitude, requires_approval, visibility)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	stmt, err := db.DB.Prepare(query)
+	err := checkCategory(e.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	err = checkCoordinates(e.Latitude, e.Longitude)
+	if err != nil {
+		return err
+	}
+
+	err = e.checkEndTime()
+	if err != nil {
+		return err
+	}
+
+	// the event, its owner host row and its tags are written together
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(query)
 
 	if err != nil {
 		return err
 	}
 	defer stmt.Close()
 
-	result, err := stmt.Exec(e.Name, e.Description, e.DateTime, e.Location, e.UserID)
+	// dates are stored in UTC so they can be compared in SQL
+	e.DateTime = e.DateTime.UTC()
+	e.EndsAt = utcTime(e.EndsAt)
+	if e.Visibility == "" {
+		e.Visibility = VisibilityPublic
+	}
+	result, err := stmt.Exec(e.Name, e.Description, e.DateTime, e.EndsAt, e.Location, e.UserID, e.CategoryID, e.OrgID, e.Latitude, e.Longitude, e.RequiresApproval, e.Visibility)
 	if err != nil {
 		return err
 	}
@@ -36,53 +103,251 @@ func (e *Event) Save() error {
 	if err != nil {
 		return err
 	}
+
+	_, err = tx.Exec(`INSERT INTO event_hosts (event_id, user_id, role, status) VALUES (?, ?, ?, ?)`,
+		id, e.UserID, RoleOwner, HostAccepted)
+	if err != nil {
+		return err
+	}
+
+	e.Tags = normalizeTags(e.Tags)
+	err = setEventTags(tx, id, e.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
 	e.ID = id
+	e.Version = 1
+	invalidateEventLists()
+	publishEventChange(EventCreated, *e)
 
 	return nil
 }
 
-func (e Event) Update() error {
+// Update only succeeds if the row still has e.Version, and bumps the version on success.
+// Tags are replaced when e.Tags is not nil, an empty slice removes them all.
+func (e *Event) Update() error {
 	query := `UPDATE events
-	SET name = ?, description = ?, location = ?, date = ?
-	WHERE id = ?`
+	SET name = ?, description = ?, location = ?, date = ?, ends_at = ?, category_id = ?, latitude = ?, longitude = ?, requires_approval = ?,
+		visibility = COALESCE(NULLIF(?, ''), visibility), version = version + 1
+	WHERE id = ? AND version = ? AND org_id IS ?`
+
+	err := checkCategory(e.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	err = checkCoordinates(e.Latitude, e.Longitude)
+	if err != nil {
+		return err
+	}
+
+	err = e.checkEndTime()
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
 
-	stmt, err := db.DB.Prepare(query)
+	stmt, err := tx.Prepare(query)
 	if err != nil {
 		return err
 	}
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(e.Name, e.Description, e.Location, e.DateTime, e.ID)
+	// the registrants hear about what changed, see notifyEventUpdate
+	var before Event
+	err = tx.QueryRow(`SELECT name, description, date, ends_at, location, visibility FROM events WHERE id = ? AND version = ? AND org_id IS ?`,
+		e.ID, e.Version, e.OrgID).Scan(&before.Name, &before.Description, &before.DateTime, &before.EndsAt, &before.Location, &before.Visibility)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	e.DateTime = e.DateTime.UTC()
+	e.EndsAt = utcTime(e.EndsAt)
+	result, err := stmt.Exec(e.Name, e.Description, e.Location, e.DateTime, e.EndsAt, e.CategoryID, e.Latitude, e.Longitude, e.RequiresApproval, e.Visibility, e.ID, e.Version, e.OrgID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	err = notifyEventUpdate(tx, before, *e)
 	if err != nil {
 		return err
 	}
 
+	if e.Tags != nil {
+		e.Tags = normalizeTags(e.Tags)
+		err = setEventTags(tx, e.ID, e.Tags)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	e.Version++
+	invalidateEvent(e.ID)
+
+	if e.Tags == nil {
+		tags, err := getEventTags([]int64{e.ID})
+		if err != nil {
+			return err
+		}
+		e.Tags = tags[e.ID]
+	}
+
+	if e.Visibility == "" {
+		err = db.DB.QueryRow(`SELECT visibility FROM events WHERE id = ?`, e.ID).Scan(&e.Visibility)
+		if err != nil {
+			return err
+		}
+	}
+	change := EventChange{Kind: EventUpdated, Event: *e}
+	if before.Visibility != e.Visibility {
+		change.PreviousVisibility = before.Visibility
+	}
+	publish(change)
+
 	return nil
 }
 
 func (e Event) Delete() error {
-	query := `DELETE FROM events WHERE id = ?`
+	query := `DELETE FROM events WHERE id = ? AND version = ? AND org_id IS ?`
 
-	stmt, err := db.DB.Prepare(query)
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(query)
 	if err != nil {
 		return err
 	}
 
 	defer stmt.Close()
 
-	_, err = stmt.Exec(e.ID)
+	result, err := stmt.Exec(e.ID, e.Version, e.OrgID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	err = notifyRegistrants(tx, e, NotifyEventCancelled, fmt.Sprintf("%s was cancelled.", e.Name))
+	if err != nil {
+		return err
+	}
+
+	// the registrants are notified, their registrations go with the event
+	_, err = tx.Exec(`DELETE FROM registration_events WHERE registration_id IN (SELECT id FROM registrations WHERE event_id = ?)`, e.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM registrations WHERE event_id = ?`, e.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM event_hosts WHERE event_id = ?`, e.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM event_tags WHERE event_id = ?`, e.ID)
 	if err != nil {
 		return err
 	}
 
+	err = deleteEventInvites(tx, e.ID)
+	if err != nil {
+		return err
+	}
+
+	err = deleteEventComments(tx, e.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM event_feedback WHERE event_id = ?`, e.ID)
+	if err != nil {
+		return err
+	}
+
+	blobKeys, err := deleteEventAttachments(tx, e.ID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	removeBlobs(blobKeys)
+	invalidateEvent(e.ID)
+	publishEventChange(EventDeleted, e)
+
 	return nil
 }
 
+// column order used by every event SELECT, matches scanEvent
+const eventColumns = "id, name, description, date, ends_at, location, user_id, version, category_id, org_id, latitude, longitude, requires_approval, visibility"
+
+// scanner is implemented by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEvent(row scanner, e *Event) error {
+	return row.Scan(&e.ID, &e.Name, &e.Description, &e.DateTime, &e.EndsAt, &e.Location, &e.UserID, &e.Version, &e.CategoryID, &e.OrgID, &e.Latitude, &e.Longitude, &e.RequiresApproval, &e.Visibility)
+}
+
 func GetAllEvents() ([]Event, error) {
+	return GetEvents(EventFilter{})
+}
+
+// GetEvents goes through the event cache when it is on.
+func GetEvents(filter EventFilter) ([]Event, error) {
+	return cachedEvents(eventListKey(filter), func() ([]Event, error) {
+		return queryEventList(filter)
+	})
+}
+
+func queryEventList(filter EventFilter) ([]Event, error) {
 	var events []Event
-	query := "SELECT * FROM events"
-	rows, err := db.DB.Query(query)
+	where, args := filter.where()
+	query := "SELECT " + eventColumns + " FROM events e WHERE " + where
+	rows, err := db.DB.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -90,63 +355,137 @@ func GetAllEvents() ([]Event, error) {
 	defer rows.Close()
 	for rows.Next() {
 		var e Event
-		err := rows.Scan(&e.ID, &e.Name, &e.Description, &e.DateTime, &e.Location, &e.UserID)
+		err := scanEvent(rows, &e)
 		if err != nil {
 			return nil, err
 		}
 		events = append(events, e)
 	}
 
+	err = attachTags(events)
+	if err != nil {
+		return nil, err
+	}
+
 	return events, nil
 }
 
-func GetEventByID(id int64) (*Event, error) {
-	query := `SELECT * FROM events WHERE id = ?`
+// where builds the SQL condition for the filter, events are aliased as e.
+func (f EventFilter) where() (string, []any) {
+	// tenant isolation, every listing stays inside one organization
+	conditions := []string{"e.org_id IS ?", "e.visibility = ?"}
+	args := []any{f.OrgID, VisibilityPublic}
 
-	row := db.DB.QueryRow(query, id)
+	if f.Category != "" {
+		conditions = append(conditions, "e.category_id = (SELECT id FROM categories WHERE slug = ?)")
+		args = append(args, f.Category)
+	}
 
-	var event Event
+	for _, tag := range normalizeTags(f.Tags) {
+		conditions = append(conditions, `e.id IN (
+			SELECT et.event_id FROM event_tags et JOIN tags t ON t.id = et.tag_id WHERE t.name = ?)`)
+		args = append(args, tag)
+	}
+
+	if f.From != nil {
+		conditions = append(conditions, "e.date >= ?")
+		args = append(args, f.From.UTC())
+	}
+	if f.To != nil {
+		conditions = append(conditions, "e.date < ?")
+		args = append(args, f.To.UTC())
+	}
 
-	err := row.Scan(&event.ID, &event.Name, &event.Description, &event.DateTime, &event.Location, &event.UserID)
+	return strings.Join(conditions, " AND "), args
+}
+
+// GetOrgEventByID only finds the event inside the given organization (nil: outside any),
+// so requests can't reach events of other tenants. Returns sql.ErrNoRows otherwise.
+func GetOrgEventByID(orgID *int64, id int64) (*Event, error) {
+	event, err := GetEventByID(id)
 	if err != nil {
-		return &event, err
+		return event, err
+	}
+	if !sameOrg(event.OrgID, orgID) {
+		return &Event{}, sql.ErrNoRows
 	}
 
-	return &event, nil
+	return event, nil
+}
+
+// GetEventByID ignores the organization, request handlers use GetOrgEventByID.
+// It goes through the event cache when it is on.
+func GetEventByID(id int64) (*Event, error) {
+	return cachedEvent(id, func() (*Event, error) {
+		return queryEvent(id)
+	})
 }
 
-func (e Event) Register(userId int64) error {
-	query := `INSERT INTO registrations (event_id, user_id) VALUES (?, ?)`
+func queryEvent(id int64) (*Event, error) {
+	query := "SELECT " + eventColumns + " FROM events WHERE id = ?"
+
+	row := db.DB.QueryRow(query, id)
 
-	stmt, err := db.DB.Prepare(query)
+	var event Event
 
+	err := scanEvent(row, &event)
 	if err != nil {
-		return err
+		return &event, err
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(e.ID, userId)
+	tags, err := getEventTags([]int64{event.ID})
 	if err != nil {
-		return err
+		return &event, err
 	}
+	event.Tags = tags[event.ID]
 
-	return nil
+	return &event, nil
 }
 
-func (e Event) DeleteRegistration(userId int64) error {
-	query := `DELETE FROM registrations WHERE event_id = ? AND user_id = ?`
+// checkCoordinates also runs outside of request binding, for events built in code.
+func checkCoordinates(latitude *float64, longitude *float64) error {
+	if latitude == nil && longitude == nil {
+		return nil
+	}
+	if latitude == nil || longitude == nil {
+		return fmt.Errorf("%w: latitude and longitude go together", ErrInvalidCoordinates)
+	}
 
-	stmt, err := db.DB.Prepare(query)
-	if err != nil {
-		return err
+	return ValidateCoordinates(*latitude, *longitude)
+}
+
+// ValidateCoordinates checks the ranges in degrees, NaN and infinities are refused too.
+func ValidateCoordinates(latitude float64, longitude float64) error {
+	if !(latitude >= -90 && latitude <= 90) {
+		return fmt.Errorf("%w: latitude must be between -90 and 90", ErrInvalidCoordinates)
+	}
+	if !(longitude >= -180 && longitude <= 180) {
+		return fmt.Errorf("%w: longitude must be between -180 and 180", ErrInvalidCoordinates)
 	}
 
-	defer stmt.Close()
+	return nil
+}
 
-	_, err = stmt.Exec(e.ID, userId)
-	if err != nil {
-		return err
+// EndTime is EndsAt, or DefaultEventDuration after the start when it isn't set.
+func (e Event) EndTime() time.Time {
+	if e.EndsAt != nil {
+		return *e.EndsAt
 	}
+	return e.DateTime.Add(DefaultEventDuration)
+}
 
+// checkEndTime also runs outside of request binding, like checkCoordinates.
+func (e Event) checkEndTime() error {
+	if e.EndsAt != nil && !e.EndsAt.After(e.DateTime) {
+		return ErrInvalidEndTime
+	}
 	return nil
 }
+
+func utcTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	utc := t.UTC()
+	return &utc
+}
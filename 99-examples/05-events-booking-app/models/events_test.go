@@ -0,0 +1,47 @@
+package models
+
+import (
+	"events-booking/db"
+	"testing"
+)
+
+func TestDeleteRemovesRegistrations(t *testing.T) {
+	e := newTestEvent(t, false)
+	userID := newTestUser(t)
+	registration, err := e.Register(userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := GetEventByID(e.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = stored.Delete()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var registrations, history int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM registrations WHERE event_id = ?`, e.ID).Scan(&registrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM registration_events WHERE registration_id = ?`, registration.ID).Scan(&history)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if registrations != 0 || history != 0 {
+		t.Errorf("%d registrations with %d history rows left after the delete", registrations, history)
+	}
+
+	// the registrant still hears about the cancellation
+	var notified int
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = ? AND event_id = ?`, userID, e.ID).Scan(&notified)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if notified == 0 {
+		t.Errorf("the registrant was not notified")
+	}
+}
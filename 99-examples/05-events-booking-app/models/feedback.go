@@ -0,0 +1,205 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"strings"
+	"time"
+)
+
+var (
+	ErrFeedbackNotOpen    = errors.New("feedback opens once the event is over")
+	ErrCannotGiveFeedback = errors.New("only attendees of the event can give feedback")
+	ErrFeedbackNotFound   = errors.New("feedback not found")
+)
+
+// Feedback is the rating of one attendee, a user has at most one per event.
+type Feedback struct {
+	EventID   int64     `json:"event_id"`
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email,omitempty"` // only in the hosts' listing
+	Rating    int       `json:"rating"`          // 1 to 5
+	Review    string    `json:"review"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// RatingSummary is what everyone sees of the feedback, Average is nil without ratings.
+type RatingSummary struct {
+	Average *float64 `json:"average"`
+	Count   int      `json:"count"`
+}
+
+// FeedbackStats is the summary for the hosts.
+type FeedbackStats struct {
+	RatingSummary
+	Distribution map[int]int `json:"distribution"`  // every rating from 1 to 5 with its count
+	Eligible     int         `json:"eligible"`      // attendees who can give feedback
+	ResponseRate float64     `json:"response_rate"` // Count / Eligible, 0 without eligible attendees
+}
+
+// feedbackStatus is the other registration status that can give feedback next
+// to attended: once anyone was checked in none, otherwise confirmed. So events
+// without check-in still get feedback.
+func feedbackStatus(q queryRower, eventID int64) (string, error) {
+	var checkIns bool
+	err := q.QueryRow(`SELECT EXISTS (SELECT 1 FROM registrations WHERE event_id = ? AND status = ?)`,
+		eventID, RegistrationAttended).Scan(&checkIns)
+	if err != nil {
+		return "", err
+	}
+
+	if checkIns {
+		return RegistrationAttended, nil
+	}
+	return RegistrationConfirmed, nil
+}
+
+// SubmitFeedback saves the user's rating, replacing the one given before. It
+// fails with ErrFeedbackNotOpen before the event's EndTime and with
+// ErrCannotGiveFeedback for users without a fitting registration. The event's
+// version stays, a rating is no edit of the event.
+func (e Event) SubmitFeedback(userID int64, rating int, review string) (*Feedback, error) {
+	now := time.Now().UTC()
+	if now.Before(e.EndTime()) {
+		return nil, ErrFeedbackNotOpen
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	status, err := feedbackStatus(tx, e.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	registration, err := getRegistration(tx, e.ID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrCannotGiveFeedback
+	}
+	if err != nil {
+		return nil, err
+	}
+	if registration.Status != RegistrationAttended && registration.Status != status {
+		return nil, ErrCannotGiveFeedback
+	}
+
+	review = strings.TrimSpace(review)
+	_, err = tx.Exec(`
+	INSERT INTO event_feedback (event_id, user_id, rating, review, created_at, updated_at)
+	VALUES (?, ?, ?, ?, ?, ?)
+	ON CONFLICT(event_id, user_id) DO UPDATE SET rating = excluded.rating, review = excluded.review, updated_at = excluded.updated_at`,
+		e.ID, userID, rating, review, now, now)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	invalidateEventDetail(e.ID)
+
+	return GetFeedback(e.ID, userID)
+}
+
+// GetFeedback returns ErrFeedbackNotFound when the user hasn't given any.
+func GetFeedback(eventID int64, userID int64) (*Feedback, error) {
+	var f Feedback
+	err := db.DB.QueryRow(`
+	SELECT event_id, user_id, rating, review, created_at, updated_at
+	FROM event_feedback WHERE event_id = ? AND user_id = ?`, eventID, userID).
+		Scan(&f.EventID, &f.UserID, &f.Rating, &f.Review, &f.CreatedAt, &f.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrFeedbackNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+// GetEventFeedback lists the feedback with the attendees' emails, oldest first.
+func GetEventFeedback(eventID int64) ([]Feedback, error) {
+	rows, err := db.DB.Query(`
+	SELECT f.event_id, f.user_id, u.email, f.rating, f.review, f.created_at, f.updated_at
+	FROM event_feedback f JOIN users u ON u.id = f.user_id
+	WHERE f.event_id = ?
+	ORDER BY f.created_at, f.user_id`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	feedback := []Feedback{}
+	for rows.Next() {
+		var f Feedback
+		err := rows.Scan(&f.EventID, &f.UserID, &f.Email, &f.Rating, &f.Review, &f.CreatedAt, &f.UpdatedAt)
+		if err != nil {
+			return nil, err
+		}
+		feedback = append(feedback, f)
+	}
+
+	return feedback, rows.Err()
+}
+
+func GetRatingSummary(eventID int64) (RatingSummary, error) {
+	var summary RatingSummary
+	err := db.DB.QueryRow(`SELECT AVG(rating), COUNT(*) FROM event_feedback WHERE event_id = ?`, eventID).
+		Scan(&summary.Average, &summary.Count)
+
+	return summary, err
+}
+
+func GetFeedbackStats(eventID int64) (*FeedbackStats, error) {
+	stats := FeedbackStats{Distribution: map[int]int{1: 0, 2: 0, 3: 0, 4: 0, 5: 0}}
+
+	rows, err := db.DB.Query(`SELECT rating, COUNT(*) FROM event_feedback WHERE event_id = ? GROUP BY rating`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sum int
+	for rows.Next() {
+		var rating, count int
+		err := rows.Scan(&rating, &count)
+		if err != nil {
+			return nil, err
+		}
+		stats.Distribution[rating] = count
+		stats.Count += count
+		sum += rating * count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if stats.Count > 0 {
+		average := float64(sum) / float64(stats.Count)
+		stats.Average = &average
+	}
+
+	status, err := feedbackStatus(db.DB, eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM registrations WHERE event_id = ? AND status IN (?, ?)`,
+		eventID, RegistrationAttended, status).Scan(&stats.Eligible)
+	if err != nil {
+		return nil, err
+	}
+
+	if stats.Eligible > 0 {
+		stats.ResponseRate = float64(stats.Count) / float64(stats.Eligible)
+	}
+
+	return &stats, nil
+}
@@ -0,0 +1,210 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"time"
+)
+
+// Host roles, the owner is also stored as events.user_id
+const (
+	RoleOwner   = "owner"
+	RoleCoHost  = "co-host"
+	RoleChecker = "checker"
+)
+
+// Host invite states
+const (
+	HostInvited  = "invited"
+	HostAccepted = "accepted"
+)
+
+var (
+	ErrHostNotFound    = errors.New("host not found for this event")
+	ErrInvalidHostRole = errors.New("role must be co-host or checker")
+	ErrAlreadyHost     = errors.New("user is already a host of this event")
+	ErrOwnerNotRemoved = errors.New("the owner can't be removed, transfer the ownership first")
+	ErrAlreadyOwner    = errors.New("user already owns this event")
+)
+
+type EventHost struct {
+	EventID   int64     `json:"event_id"`
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	Status    string    `json:"status"`
+	InvitedBy *int64    `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func GetEventHosts(eventID int64) ([]EventHost, error) {
+	query := `
+	SELECT h.event_id, h.user_id, u.email, h.role, h.status, h.invited_by, h.created_at
+	FROM event_hosts h JOIN users u ON u.id = h.user_id
+	WHERE h.event_id = ?
+	ORDER BY h.created_at`
+
+	rows, err := db.DB.Query(query, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hosts []EventHost
+
+	for rows.Next() {
+		var h EventHost
+		err := rows.Scan(&h.EventID, &h.UserID, &h.Email, &h.Role, &h.Status, &h.InvitedBy, &h.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		hosts = append(hosts, h)
+	}
+
+	return hosts, nil
+}
+
+// GetHostRole returns the accepted role of the user for the event, or "" if there is none.
+func GetHostRole(eventID int64, userID int64) (string, error) {
+	query := `SELECT role FROM event_hosts WHERE event_id = ? AND user_id = ? AND status = ?`
+
+	var role string
+	err := db.DB.QueryRow(query, eventID, userID, HostAccepted).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+// InviteHost adds the user as a pending host, the invite counts once they accept it.
+func (e Event) InviteHost(userID int64, role string, invitedBy int64) error {
+	if role != RoleCoHost && role != RoleChecker {
+		return ErrInvalidHostRole
+	}
+
+	query := `
+	INSERT INTO event_hosts (event_id, user_id, role, status, invited_by)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(event_id, user_id) DO NOTHING`
+
+	result, err := db.DB.Exec(query, e.ID, userID, role, HostInvited, invitedBy)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAlreadyHost
+	}
+
+	return nil
+}
+
+func (e Event) AcceptHostInvite(userID int64) error {
+	query := `UPDATE event_hosts SET status = ? WHERE event_id = ? AND user_id = ? AND status = ?`
+
+	result, err := db.DB.Exec(query, HostAccepted, e.ID, userID, HostInvited)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrHostNotFound
+	}
+
+	return nil
+}
+
+// RemoveHost drops a host or a pending invite. The owner has to transfer the event first.
+func (e Event) RemoveHost(userID int64) error {
+	if userID == e.UserID {
+		return ErrOwnerNotRemoved
+	}
+
+	result, err := db.DB.Exec(`DELETE FROM event_hosts WHERE event_id = ? AND user_id = ?`, e.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrHostNotFound
+	}
+
+	return nil
+}
+
+// TransferOwnership hands the event to an accepted host, the previous owner stays on as co-host.
+// Fails with ErrAlreadyOwner for the current owner, the event would be left without one.
+func (e *Event) TransferOwnership(newOwnerID int64) error {
+	if newOwnerID == e.UserID {
+		return ErrAlreadyOwner
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE event_hosts SET role = ? WHERE event_id = ? AND user_id = ? AND status = ?`,
+		RoleOwner, e.ID, newOwnerID, HostAccepted)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrHostNotFound
+	}
+
+	_, err = tx.Exec(`UPDATE event_hosts SET role = ? WHERE event_id = ? AND user_id = ?`,
+		RoleCoHost, e.ID, e.UserID)
+	if err != nil {
+		return err
+	}
+
+	result, err = tx.Exec(`UPDATE events SET user_id = ?, version = version + 1 WHERE id = ? AND version = ?`,
+		newOwnerID, e.ID, e.Version)
+	if err != nil {
+		return err
+	}
+
+	affected, err = result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrVersionConflict
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	e.UserID = newOwnerID
+	e.Version++
+	invalidateEvent(e.ID)
+	publishEventChange(EventUpdated, *e)
+
+	return nil
+}
@@ -0,0 +1,92 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"time"
+)
+
+// IdempotentResponse is the first response sent for an Idempotency-Key.
+// StatusCode is 0 while the first request is still running.
+type IdempotentResponse struct {
+	RequestHash string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	LockedUntil time.Time
+}
+
+// ClaimIdempotencyKey reserves the key for a request. It returns nil when the caller
+// got the key and should run the request, otherwise the stored (or in-flight) entry.
+// Expired entries and abandoned locks are taken over.
+func ClaimIdempotencyKey(userID int64, key string, requestHash string, lockFor time.Duration, ttl time.Duration) (*IdempotentResponse, error) {
+	now := time.Now()
+
+	// the key is free when there is no row, or the row expired, or its request died while running
+	query := `
+	INSERT INTO idempotency_keys (user_id, key, request_hash, locked_until, expires_at)
+	VALUES (?, ?, ?, ?, ?)
+	ON CONFLICT(user_id, key) DO UPDATE SET
+		request_hash = excluded.request_hash, status_code = NULL, content_type = NULL, body = NULL,
+		locked_until = excluded.locked_until, expires_at = excluded.expires_at
+	WHERE idempotency_keys.expires_at < ?
+		OR (idempotency_keys.status_code IS NULL AND idempotency_keys.locked_until < ?)`
+
+	result, err := db.DB.Exec(query, userID, key, requestHash, now.Add(lockFor).UnixMilli(), now.Add(ttl).UnixMilli(),
+		now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return nil, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if affected == 1 {
+		return nil, nil
+	}
+
+	var stored IdempotentResponse
+	var statusCode sql.NullInt64
+	var contentType sql.NullString
+	var lockedUntil sql.NullInt64
+
+	err = db.DB.QueryRow(`
+	SELECT request_hash, status_code, content_type, body, locked_until
+	FROM idempotency_keys WHERE user_id = ? AND key = ?`, userID, key).
+		Scan(&stored.RequestHash, &statusCode, &contentType, &stored.Body, &lockedUntil)
+	if errors.Is(err, sql.ErrNoRows) {
+		// deleted in between, the client can simply retry
+		return &IdempotentResponse{RequestHash: requestHash, LockedUntil: now}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stored.StatusCode = int(statusCode.Int64)
+	stored.ContentType = contentType.String
+	stored.LockedUntil = time.UnixMilli(lockedUntil.Int64)
+
+	return &stored, nil
+}
+
+func SaveIdempotentResponse(userID int64, key string, statusCode int, contentType string, body []byte) error {
+	query := `
+	UPDATE idempotency_keys SET status_code = ?, content_type = ?, body = ?, locked_until = NULL
+	WHERE user_id = ? AND key = ?`
+
+	_, err := db.DB.Exec(query, statusCode, contentType, body, userID, key)
+	return err
+}
+
+// ReleaseIdempotencyKey forgets the key, e.g. after a server error so a retry runs again.
+func ReleaseIdempotencyKey(userID int64, key string) error {
+	_, err := db.DB.Exec(`DELETE FROM idempotency_keys WHERE user_id = ? AND key = ?`, userID, key)
+	return err
+}
+
+func DeleteExpiredIdempotencyKeys() error {
+	_, err := db.DB.Exec(`DELETE FROM idempotency_keys WHERE expires_at < ?`, time.Now().UnixMilli())
+	return err
+}
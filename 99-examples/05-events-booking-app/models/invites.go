@@ -0,0 +1,274 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"events-booking/utils"
+	"time"
+)
+
+// Event visibilities
+const (
+	VisibilityPublic   = "public"   // listed and open to everyone
+	VisibilityUnlisted = "unlisted" // open to everyone who knows the ID, never listed
+	VisibilityPrivate  = "private"  // only for hosts and users let in by an invite or the access code
+)
+
+var (
+	ErrInviteNotFound    = errors.New("invite not found")
+	ErrInviteUnavailable = errors.New("the invite expired, was revoked or is used up")
+	ErrWrongAccessCode   = errors.New("wrong access code")
+)
+
+type EventInvite struct {
+	ID        int64      `json:"id"`
+	EventID   int64      `json:"event_id"`
+	CreatedBy int64      `json:"created_by"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	RevokedAt *time.Time `json:"revoked_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+const inviteColumns = `id, event_id, created_by, max_uses, uses, expires_at, revoked_at, created_at`
+
+// CanViewEvent reports whether the user may see the event, userID is 0 for
+// anonymous callers. Private events are only visible to their hosts (invited
+// ones too, so they can accept), to users let in by an invite or the access
+// code, and to users who registered while the event was open.
+func CanViewEvent(userID int64, e *Event) (bool, error) {
+	if e.Visibility != VisibilityPrivate {
+		return true, nil
+	}
+	if userID == 0 {
+		return false, nil
+	}
+
+	var allowed bool
+	err := db.DB.QueryRow(`
+	SELECT EXISTS (SELECT 1 FROM event_hosts WHERE event_id = ? AND user_id = ?)
+		OR EXISTS (SELECT 1 FROM event_access WHERE event_id = ? AND user_id = ?)
+		OR EXISTS (SELECT 1 FROM registrations WHERE event_id = ? AND user_id = ? AND status != ?)`,
+		e.ID, userID, e.ID, userID, e.ID, userID, RegistrationCancelled).Scan(&allowed)
+
+	return allowed, err
+}
+
+// FilterVisibleEvents keeps the events of a list the user may see, for lists
+// not going through EventFilter. Unlisted events are left out like private
+// ones unless the user hosts them.
+func FilterVisibleEvents(userID int64, list []Event) ([]Event, error) {
+	visible := list[:0:0]
+	for _, e := range list {
+		if e.Visibility == VisibilityPublic {
+			visible = append(visible, e)
+			continue
+		}
+
+		role, err := GetHostRole(e.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if role != "" {
+			visible = append(visible, e)
+		}
+	}
+
+	return visible, nil
+}
+
+// CreateEventInvite returns the invite and its token in plain text, the only time it is available.
+func CreateEventInvite(eventID int64, createdBy int64, maxUses int, expiresAt time.Time) (*EventInvite, string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invite := EventInvite{
+		EventID:   eventID,
+		CreatedBy: createdBy,
+		MaxUses:   maxUses,
+		ExpiresAt: expiresAt.UTC(),
+		CreatedAt: time.Now().UTC(),
+	}
+
+	result, err := db.DB.Exec(`
+	INSERT INTO event_invites (event_id, token_hash, created_by, max_uses, expires_at, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`,
+		invite.EventID, hashSecret(token), invite.CreatedBy, invite.MaxUses, invite.ExpiresAt, invite.CreatedAt)
+	if err != nil {
+		return nil, "", err
+	}
+
+	invite.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &invite, token, nil
+}
+
+func GetEventInvites(eventID int64) ([]EventInvite, error) {
+	rows, err := db.DB.Query(`SELECT `+inviteColumns+` FROM event_invites WHERE event_id = ? ORDER BY id`, eventID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invites := []EventInvite{}
+	for rows.Next() {
+		var i EventInvite
+		err := rows.Scan(&i.ID, &i.EventID, &i.CreatedBy, &i.MaxUses, &i.Uses, &i.ExpiresAt, &i.RevokedAt, &i.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		invites = append(invites, i)
+	}
+
+	return invites, rows.Err()
+}
+
+// RevokeEventInvite stops the invite from letting anyone else in, users who
+// already used it keep their access.
+func RevokeEventInvite(eventID int64, id int64) error {
+	result, err := db.DB.Exec(`UPDATE event_invites SET revoked_at = ? WHERE id = ? AND event_id = ? AND revoked_at IS NULL`,
+		time.Now().UTC(), id, eventID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInviteNotFound
+	}
+
+	return nil
+}
+
+// RedeemEventInvite lets the user into the event, using up one use of the invite.
+// Users already let in don't use it up again.
+func RedeemEventInvite(eventID int64, userID int64, token string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inviteID int64
+	err = tx.QueryRow(`SELECT id FROM event_invites WHERE event_id = ? AND token_hash = ?`, eventID, hashSecret(token)).Scan(&inviteID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrInviteNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	granted, err := hasEventAccess(tx, eventID, userID)
+	if err != nil || granted {
+		return err
+	}
+
+	// the conditions are checked in the UPDATE so concurrent uses can't go over max_uses
+	result, err := tx.Exec(`
+	UPDATE event_invites SET uses = uses + 1
+	WHERE id = ? AND uses < max_uses AND revoked_at IS NULL AND expires_at > ?`,
+		inviteID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrInviteUnavailable
+	}
+
+	err = grantEventAccess(tx, eventID, userID, &inviteID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// SetEventAccessCode sets the code that lets anyone knowing it into the event,
+// an empty code removes it. It is stored hashed like a password.
+func SetEventAccessCode(eventID int64, code string) error {
+	var codeHash *string
+	if code != "" {
+		hash, err := utils.HashNewPassword(code)
+		if err != nil {
+			return err
+		}
+		codeHash = &hash
+	}
+
+	_, err := db.DB.Exec(`UPDATE events SET access_code_hash = ? WHERE id = ?`, codeHash, eventID)
+	return err
+}
+
+func HasEventAccessCode(eventID int64) (bool, error) {
+	var set bool
+	err := db.DB.QueryRow(`SELECT access_code_hash IS NOT NULL FROM events WHERE id = ?`, eventID).Scan(&set)
+	return set, err
+}
+
+// RedeemAccessCode lets the user into the event when the code is right.
+func RedeemAccessCode(eventID int64, userID int64, code string) error {
+	var codeHash sql.NullString
+	err := db.DB.QueryRow(`SELECT access_code_hash FROM events WHERE id = ?`, eventID).Scan(&codeHash)
+	if err != nil {
+		return err
+	}
+	if !codeHash.Valid || !utils.CheckValidHashPassword(code, codeHash.String) {
+		return ErrWrongAccessCode
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	granted, err := hasEventAccess(tx, eventID, userID)
+	if err != nil || granted {
+		return err
+	}
+
+	err = grantEventAccess(tx, eventID, userID, nil)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func hasEventAccess(tx *sql.Tx, eventID int64, userID int64) (bool, error) {
+	var granted bool
+	err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM event_access WHERE event_id = ? AND user_id = ?)`, eventID, userID).Scan(&granted)
+	return granted, err
+}
+
+func grantEventAccess(tx *sql.Tx, eventID int64, userID int64, inviteID *int64) error {
+	_, err := tx.Exec(`INSERT INTO event_access (event_id, user_id, invite_id, granted_at) VALUES (?, ?, ?, ?)`,
+		eventID, userID, inviteID, time.Now().UTC())
+	return err
+}
+
+// deleteEventInvites removes the invites and granted access of a deleted event.
+func deleteEventInvites(tx *sql.Tx, eventID int64) error {
+	_, err := tx.Exec(`DELETE FROM event_access WHERE event_id = ?`, eventID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`DELETE FROM event_invites WHERE event_id = ?`, eventID)
+	return err
+}
@@ -0,0 +1,326 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"events-booking/utils"
+	"strings"
+	"time"
+)
+
+var (
+	ErrMFAAlreadyEnabled    = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnabled        = errors.New("two-factor authentication is not set up")
+	ErrInvalidMFACode       = errors.New("invalid code")
+	ErrMFAChallengeNotFound = errors.New("login not found or expired")
+	ErrTooManyMFAAttempts   = errors.New("too many wrong codes")
+)
+
+const (
+	recoveryCodeCount = 10
+	// how long a user has to enter the code after the password
+	mfaChallengeTTL = 5 * time.Minute
+	// wrong codes allowed per user while their challenges are valid, so new
+	// logins with the password don't give new guesses
+	maxMFAAttempts = 10
+)
+
+// TOTPEnrollment is shown to the user once, to add the account to an authenticator app.
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type MFAStatus struct {
+	Enabled           bool `json:"enabled"`
+	RecoveryCodesLeft int  `json:"recovery_codes_left"`
+}
+
+// EnrollTOTP creates a new secret, which only protects logins after ConfirmTOTP.
+// Starting over replaces a secret that was never confirmed.
+func EnrollTOTP(userID int64) (*TOTPEnrollment, error) {
+	enabled, err := MFAEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	profile, err := GetProfile(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = db.DB.Exec(`INSERT OR REPLACE INTO user_totp (user_id, secret, created_at) VALUES (?, ?, ?)`,
+		userID, secret, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	return &TOTPEnrollment{Secret: secret, URI: utils.TOTPURI(profile.Email, secret)}, nil
+}
+
+// ConfirmTOTP turns two-factor authentication on once the user proves the app
+// has the secret, and returns the recovery codes, which are not stored in plain.
+func ConfirmTOTP(userID int64, code string) ([]string, error) {
+	var secret string
+	err := db.DB.QueryRow(`SELECT secret FROM user_totp WHERE user_id = ? AND confirmed_at IS NULL`, userID).Scan(&secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrMFANotEnabled
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	_, err = db.DB.Exec(`UPDATE user_totp SET confirmed_at = ?, last_step = ? WHERE user_id = ?`,
+		time.Now().UTC(), step, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return replaceRecoveryCodes(userID)
+}
+
+// RegenerateRecoveryCodes invalidates the old recovery codes, the code proves
+// it is still the user.
+func RegenerateRecoveryCodes(userID int64, code string) ([]string, error) {
+	err := VerifyMFACode(userID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	return replaceRecoveryCodes(userID)
+}
+
+// DisableTOTP turns two-factor authentication off, with a code from the app or a recovery code.
+func DisableTOTP(userID int64, code string) error {
+	err := VerifyMFACode(userID, code)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, query := range []string{
+		`DELETE FROM user_totp WHERE user_id = ?`,
+		`DELETE FROM mfa_recovery_codes WHERE user_id = ?`,
+		`DELETE FROM mfa_challenges WHERE user_id = ?`,
+	} {
+		_, err = tx.Exec(query, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func MFAEnabled(userID int64) (bool, error) {
+	var enabled bool
+	err := db.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL)`,
+		userID).Scan(&enabled)
+	return enabled, err
+}
+
+func GetMFAStatus(userID int64) (*MFAStatus, error) {
+	var status MFAStatus
+	var err error
+
+	status.Enabled, err = MFAEnabled(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DB.QueryRow(`SELECT COUNT(*) FROM mfa_recovery_codes WHERE user_id = ? AND used_at IS NULL`,
+		userID).Scan(&status.RecoveryCodesLeft)
+	if err != nil {
+		return nil, err
+	}
+
+	return &status, nil
+}
+
+// VerifyMFACode accepts a code from the authenticator app or an unused recovery
+// code. Every code works once: an app code is refused if it is not newer than
+// the last one accepted, a recovery code is marked as used.
+func VerifyMFACode(userID int64, code string) error {
+	var secret string
+	err := db.DB.QueryRow(`SELECT secret FROM user_totp WHERE user_id = ? AND confirmed_at IS NOT NULL`, userID).Scan(&secret)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrMFANotEnabled
+	}
+	if err != nil {
+		return err
+	}
+
+	step, ok := utils.ValidateTOTP(secret, code, time.Now())
+	if ok {
+		// two requests with the same code race here, only one moves last_step
+		result, err := db.DB.Exec(`UPDATE user_totp SET last_step = ? WHERE user_id = ? AND last_step < ?`, step, userID, step)
+		if err != nil {
+			return err
+		}
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if updated == 0 {
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	result, err := db.DB.Exec(`UPDATE mfa_recovery_codes SET used_at = ? WHERE user_id = ? AND code_hash = ? AND used_at IS NULL`,
+		time.Now().UTC(), userID, hashSecret(normalizeRecoveryCode(code)))
+	if err != nil {
+		return err
+	}
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrInvalidMFACode
+	}
+
+	return nil
+}
+
+// StartMFAChallenge is called after the password was checked, the returned
+// token stands for the half finished login until FinishMFAChallenge.
+func StartMFAChallenge(userID int64) (string, error) {
+	now := time.Now()
+
+	// expired challenges of abandoned logins are cleaned up on the way
+	_, err := db.DB.Exec(`DELETE FROM mfa_challenges WHERE expires_at < ?`, now.UnixMilli())
+	if err != nil {
+		return "", err
+	}
+
+	token, err := randomString(32)
+	if err != nil {
+		return "", err
+	}
+
+	_, err = db.DB.Exec(`INSERT INTO mfa_challenges (token_hash, user_id, expires_at) VALUES (?, ?, ?)`,
+		hashSecret(token), userID, now.Add(mfaChallengeTTL).UnixMilli())
+	if err != nil {
+		return "", err
+	}
+
+	return token, nil
+}
+
+// FinishMFAChallenge checks the second factor of the login and returns the user id.
+// Wrong codes count against the user, not the challenge, see maxMFAAttempts.
+func FinishMFAChallenge(token string, code string) (int64, error) {
+	now := time.Now().UnixMilli()
+
+	var userID int64
+	err := db.DB.QueryRow(`SELECT user_id FROM mfa_challenges WHERE token_hash = ? AND expires_at >= ?`,
+		hashSecret(token), now).Scan(&userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, ErrMFAChallengeNotFound
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	// the attempt is counted before the code is checked, in the same statement
+	// as the limit, so parallel requests can't all slip in under it
+	result, err := db.DB.Exec(`UPDATE mfa_challenges SET attempts = attempts + 1
+		WHERE token_hash = ? AND expires_at >= ?
+		AND (SELECT COALESCE(SUM(attempts), 0) FROM mfa_challenges WHERE user_id = ? AND expires_at >= ?) < ?`,
+		hashSecret(token), now, userID, now, maxMFAAttempts)
+	if err != nil {
+		return 0, err
+	}
+	reserved, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	if reserved == 0 {
+		// a parallel request may have finished the login in the meantime
+		err = db.DB.QueryRow(`SELECT user_id FROM mfa_challenges WHERE token_hash = ? AND expires_at >= ?`,
+			hashSecret(token), now).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrMFAChallengeNotFound
+		}
+		if err != nil {
+			return 0, err
+		}
+		return 0, ErrTooManyMFAAttempts
+	}
+
+	err = VerifyMFACode(userID, code)
+	if err != nil {
+		return 0, err
+	}
+
+	// the user is in, which also resets the wrong attempts
+	_, err = db.DB.Exec(`DELETE FROM mfa_challenges WHERE user_id = ?`, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+func replaceRecoveryCodes(userID int64) ([]string, error) {
+	codes := make([]string, recoveryCodeCount)
+	for i := range codes {
+		random, err := randomString(5)
+		if err != nil {
+			return nil, err
+		}
+		// xxxxx-xxxxx is easier to copy by hand
+		codes[i] = random[:5] + "-" + random[5:]
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`DELETE FROM mfa_recovery_codes WHERE user_id = ?`, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, code := range codes {
+		_, err = tx.Exec(`INSERT INTO mfa_recovery_codes (user_id, code_hash) VALUES (?, ?)`,
+			userID, hashSecret(normalizeRecoveryCode(code)))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
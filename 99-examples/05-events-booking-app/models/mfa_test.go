@@ -0,0 +1,63 @@
+package models
+
+import (
+	"errors"
+	"events-booking/db"
+	"events-booking/utils"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFinishMFAChallengeLimitsParallelGuesses(t *testing.T) {
+	userID := newTestUser(t)
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.DB.Exec(`INSERT INTO user_totp (user_id, secret, confirmed_at, created_at) VALUES (?, ?, ?, ?)`,
+		userID, secret, time.Now().UTC(), time.Now().UTC())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a few password logins, each guessing in parallel
+	var tokens []string
+	for range 3 {
+		token, err := StartMFAChallenge(userID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		tokens = append(tokens, token)
+	}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	wrong, refused := 0, 0
+	for i := range 3 * maxMFAAttempts {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := FinishMFAChallenge(tokens[i%len(tokens)], "not-a-code")
+
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case errors.Is(err, ErrInvalidMFACode):
+				wrong++
+			case errors.Is(err, ErrTooManyMFAAttempts):
+				refused++
+			default:
+				t.Errorf("FinishMFAChallenge: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if wrong != maxMFAAttempts {
+		t.Errorf("%d codes were checked, want %d", wrong, maxMFAAttempts)
+	}
+	if refused != 2*maxMFAAttempts {
+		t.Errorf("%d guesses were refused, want %d", refused, 2*maxMFAAttempts)
+	}
+}
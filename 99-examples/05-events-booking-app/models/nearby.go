@@ -0,0 +1,115 @@
+package models
+
+import (
+	"events-booking/db"
+	"math"
+	"sort"
+)
+
+// mean radius of the earth, close enough for distances between events
+const earthRadiusKm = 6371.0
+
+type NearbyEvent struct {
+	Event
+	DistanceKm float64 `json:"distance_km"`
+}
+
+// GetNearbyEvents lists the events of the filter with coordinates within radiusKm
+// of the given point, closest first. Events without coordinates are left out.
+// SQL only narrows down to a bounding box around the circle, the exact distance
+// is computed here.
+func GetNearbyEvents(filter EventFilter, latitude float64, longitude float64, radiusKm float64) ([]NearbyEvent, error) {
+	err := ValidateCoordinates(latitude, longitude)
+	if err != nil {
+		return nil, err
+	}
+
+	where, args := filter.where()
+	box, boxArgs := boundingBox(latitude, longitude, radiusKm)
+	query := "SELECT " + eventColumns + " FROM events e WHERE " + where + " AND " + box
+	rows, err := db.DB.Query(query, append(args, boxArgs...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []Event
+	var distances []float64
+	for rows.Next() {
+		var e Event
+		err := scanEvent(rows, &e)
+		if err != nil {
+			return nil, err
+		}
+
+		// the corners of the box are further away than the radius
+		distance := haversineKm(latitude, longitude, *e.Latitude, *e.Longitude)
+		if distance > radiusKm {
+			continue
+		}
+		events = append(events, e)
+		distances = append(distances, distance)
+	}
+	err = rows.Err()
+	if err != nil {
+		return nil, err
+	}
+
+	err = attachTags(events)
+	if err != nil {
+		return nil, err
+	}
+
+	nearby := make([]NearbyEvent, len(events))
+	for i, e := range events {
+		nearby[i] = NearbyEvent{Event: e, DistanceKm: math.Round(distances[i]*1000) / 1000}
+	}
+	sort.SliceStable(nearby, func(i, j int) bool {
+		if nearby[i].DistanceKm != nearby[j].DistanceKm {
+			return nearby[i].DistanceKm < nearby[j].DistanceKm
+		}
+		return nearby[i].DateTime.Before(nearby[j].DateTime)
+	})
+
+	return nearby, nil
+}
+
+// boundingBox returns the SQL condition for the latitude/longitude range
+// containing the circle. Near a pole every longitude is in range, and a box
+// crossing the antimeridian is split into two longitude ranges.
+func boundingBox(latitude float64, longitude float64, radiusKm float64) (string, []any) {
+	angle := radiusKm / earthRadiusKm // in radians
+	deltaLat := angle * 180 / math.Pi
+	minLat, maxLat := latitude-deltaLat, latitude+deltaLat
+
+	if minLat <= -90 || maxLat >= 90 {
+		return "e.latitude BETWEEN ? AND ? AND e.longitude IS NOT NULL", []any{math.Max(minLat, -90), math.Min(maxLat, 90)}
+	}
+
+	ratio := math.Sin(angle) / math.Cos(latitude*math.Pi/180)
+	if ratio >= 1 {
+		return "e.latitude BETWEEN ? AND ? AND e.longitude IS NOT NULL", []any{minLat, maxLat}
+	}
+	deltaLon := math.Asin(ratio) * 180 / math.Pi
+	minLon, maxLon := longitude-deltaLon, longitude+deltaLon
+
+	switch {
+	case minLon < -180:
+		return "e.latitude BETWEEN ? AND ? AND (e.longitude >= ? OR e.longitude <= ?)", []any{minLat, maxLat, minLon + 360, maxLon}
+	case maxLon > 180:
+		return "e.latitude BETWEEN ? AND ? AND (e.longitude >= ? OR e.longitude <= ?)", []any{minLat, maxLat, minLon, maxLon - 360}
+	default:
+		return "e.latitude BETWEEN ? AND ? AND e.longitude BETWEEN ? AND ?", []any{minLat, maxLat, minLon, maxLon}
+	}
+}
+
+// haversineKm is the great-circle distance between two points given in degrees.
+func haversineKm(lat1 float64, lon1 float64, lat2 float64, lon2 float64) float64 {
+	toRad := math.Pi / 180
+	dLat := (lat2 - lat1) * toRad
+	dLon := (lon2 - lon1) * toRad
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRad)*math.Cos(lat2*toRad)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadiusKm * math.Asin(math.Min(1, math.Sqrt(a)))
+}
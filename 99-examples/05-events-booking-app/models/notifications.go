@@ -0,0 +1,169 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrNotificationNotFound = errors.New("notification not found")
+
+// Notification is an entry of a user's in-app inbox. The event may be gone by
+// the time it is read, so its name is kept with it.
+type Notification struct {
+	ID        int64      `json:"id"`
+	Kind      string     `json:"kind"` // NotifyEventUpdated, NotifyEventRescheduled or NotifyEventCancelled
+	EventID   int64      `json:"event_id"`
+	EventName string     `json:"event_name"`
+	Message   string     `json:"message"`
+	CreatedAt time.Time  `json:"created_at"`
+	ReadAt    *time.Time `json:"read_at"`
+}
+
+// the users column holding the preference of each kind of inbox notification
+var notificationPrefColumns = map[string]string{
+	NotifyEventUpdated:     "notify_event_updates",
+	NotifyEventRescheduled: "notify_event_reschedules",
+	NotifyEventCancelled:   "notify_event_cancellations",
+}
+
+// notifyRegistrants puts a notification in the inbox of everyone with a pending
+// or confirmed registration for the event who has the kind turned on. It runs
+// in the transaction of the change, so the notifications come with it or not at all.
+func notifyRegistrants(tx *sql.Tx, e Event, kind string, message string) error {
+	_, err := tx.Exec(`
+	INSERT INTO notifications (user_id, kind, event_id, event_name, message, created_at)
+	SELECT r.user_id, ?, ?, ?, ?, ?
+	FROM registrations r JOIN users u ON u.id = r.user_id
+	WHERE r.event_id = ? AND r.status IN (?, ?) AND u.deleted_at IS NULL AND u.`+notificationPrefColumns[kind]+` = 1`,
+		kind, e.ID, e.Name, message, time.Now().UTC(),
+		e.ID, RegistrationPending, RegistrationConfirmed)
+
+	return err
+}
+
+// notifyEventUpdate compares the event before and after an update and sends one
+// notification naming every change. Moving the date or end is a reschedule,
+// other changes attendees would notice are an update. Tags, visibility and the
+// like don't notify anyone.
+func notifyEventUpdate(tx *sql.Tx, before Event, after Event) error {
+	const layout = "Mon, 2 Jan 2006 15:04 MST"
+
+	kind := NotifyEventUpdated
+	var changes []string
+
+	if before.Name != after.Name {
+		changes = append(changes, fmt.Sprintf("%s is now called %s.", before.Name, after.Name))
+	}
+
+	startMoved := !before.DateTime.Equal(after.DateTime)
+	endMoved := !before.EndTime().Equal(after.EndTime())
+	lengthChanged := before.EndTime().Sub(before.DateTime) != after.EndTime().Sub(after.DateTime)
+	switch {
+	case startMoved && lengthChanged:
+		kind = NotifyEventRescheduled
+		changes = append(changes, fmt.Sprintf("%s now starts %s and ends %s.", after.Name, after.DateTime.Format(layout), after.EndTime().Format(layout)))
+	case startMoved:
+		kind = NotifyEventRescheduled
+		changes = append(changes, fmt.Sprintf("%s now starts %s.", after.Name, after.DateTime.Format(layout)))
+	case endMoved:
+		kind = NotifyEventRescheduled
+		changes = append(changes, fmt.Sprintf("%s now ends %s.", after.Name, after.EndTime().Format(layout)))
+	}
+
+	if before.Location != after.Location {
+		changes = append(changes, fmt.Sprintf("%s moved to %s.", after.Name, after.Location))
+	}
+	if before.Description != after.Description {
+		changes = append(changes, fmt.Sprintf("The details of %s changed.", after.Name))
+	}
+
+	if len(changes) == 0 {
+		return nil
+	}
+	return notifyRegistrants(tx, after, kind, strings.Join(changes, " "))
+}
+
+// GetNotifications pages through the user's inbox, newest first. before is the
+// ID of the last notification of the previous page, 0 for the first page. The
+// returned ID is the one to pass for the next page, 0 when there is none.
+// A limit of 0 returns all of them.
+func GetNotifications(userID int64, unreadOnly bool, before int64, limit int) ([]Notification, int64, error) {
+	// one more than asked for tells whether there is a next page, -1 is no limit in SQLite
+	sqlLimit := -1
+	if limit > 0 {
+		sqlLimit = limit + 1
+	}
+
+	rows, err := db.DB.Query(`
+	SELECT id, kind, event_id, event_name, message, created_at, read_at
+	FROM notifications
+	WHERE user_id = ? AND (? = 0 OR read_at IS NULL) AND (? = 0 OR id < ?)
+	ORDER BY id DESC LIMIT ?`, userID, unreadOnly, before, before, sqlLimit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []Notification{}
+	for rows.Next() {
+		var n Notification
+		err := rows.Scan(&n.ID, &n.Kind, &n.EventID, &n.EventName, &n.Message, &n.CreatedAt, &n.ReadAt)
+		if err != nil {
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var next int64
+	if limit > 0 && len(notifications) > limit {
+		notifications = notifications[:limit]
+		next = notifications[limit-1].ID
+	}
+
+	return notifications, next, nil
+}
+
+func CountUnreadNotifications(userID int64) (int, error) {
+	var count int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM notifications WHERE user_id = ? AND read_at IS NULL`, userID).Scan(&count)
+
+	return count, err
+}
+
+// MarkNotificationRead returns ErrNotificationNotFound for notifications of
+// other users. Marking one again keeps the first read time.
+func MarkNotificationRead(userID int64, id int64) error {
+	result, err := db.DB.Exec(`UPDATE notifications SET read_at = COALESCE(read_at, ?) WHERE id = ? AND user_id = ?`,
+		time.Now().UTC(), id, userID)
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrNotificationNotFound
+	}
+
+	return nil
+}
+
+// MarkAllNotificationsRead returns how many notifications were unread.
+func MarkAllNotificationsRead(userID int64) (int64, error) {
+	result, err := db.DB.Exec(`UPDATE notifications SET read_at = ? WHERE user_id = ? AND read_at IS NULL`,
+		time.Now().UTC(), userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,234 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"time"
+)
+
+// Organization visibility: who may see the organization's events
+const (
+	OrgPublic      = "public"
+	OrgMembersOnly = "members"
+)
+
+// Organization member roles
+const (
+	OrgRoleAdmin  = "admin"
+	OrgRoleMember = "member"
+)
+
+var (
+	ErrOrgNotFound       = errors.New("organization not found")
+	ErrInvalidOrgRole    = errors.New("role must be admin or member")
+	ErrAlreadyOrgMember  = errors.New("user is already a member of this organization")
+	ErrOrgMemberNotFound = errors.New("user is not a member of this organization")
+	ErrLastOrgAdmin      = errors.New("an organization needs at least one admin")
+)
+
+type Organization struct {
+	ID         int64     `json:"id"`
+	Name       string    `json:"name" binding:"required,max=100"`
+	Slug       string    `json:"slug" binding:"required,max=50,alphanum"`
+	Visibility string    `json:"visibility" binding:"omitempty,oneof=public members"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OrgMember struct {
+	UserID    int64     `json:"user_id"`
+	Email     string    `json:"email"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Save creates the organization with the creator as its first admin.
+func (o *Organization) Save(creatorID int64) error {
+	if o.Visibility == "" {
+		o.Visibility = OrgPublic
+	}
+	o.CreatedAt = time.Now().UTC()
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`INSERT INTO organizations (name, slug, visibility, created_at) VALUES (?, ?, ?, ?)`,
+		o.Name, o.Slug, o.Visibility, o.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`INSERT INTO org_members (org_id, user_id, role, created_at) VALUES (?, ?, ?, ?)`,
+		id, creatorID, OrgRoleAdmin, o.CreatedAt)
+	if err != nil {
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+
+	o.ID = id
+
+	return nil
+}
+
+// Update changes the name and visibility, the slug stays as it is used in URLs.
+func (o Organization) Update() error {
+	_, err := db.DB.Exec(`UPDATE organizations SET name = ?, visibility = ? WHERE id = ?`, o.Name, o.Visibility, o.ID)
+	return err
+}
+
+func GetOrganizationBySlug(slug string) (*Organization, error) {
+	query := `SELECT id, name, slug, visibility, created_at FROM organizations WHERE slug = ?`
+
+	var o Organization
+	err := db.DB.QueryRow(query, slug).Scan(&o.ID, &o.Name, &o.Slug, &o.Visibility, &o.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &o, nil
+}
+
+// GetUserOrganizations lists the organizations the user is a member of.
+func GetUserOrganizations(userID int64) ([]Organization, error) {
+	query := `
+	SELECT o.id, o.name, o.slug, o.visibility, o.created_at
+	FROM organizations o JOIN org_members m ON m.org_id = o.id
+	WHERE m.user_id = ?
+	ORDER BY o.name`
+
+	rows, err := db.DB.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orgs := []Organization{}
+
+	for rows.Next() {
+		var o Organization
+		err := rows.Scan(&o.ID, &o.Name, &o.Slug, &o.Visibility, &o.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, o)
+	}
+
+	return orgs, nil
+}
+
+// GetOrgRole returns the user's role in the organization, or "" if they are not a member.
+func GetOrgRole(orgID int64, userID int64) (string, error) {
+	var role string
+	err := db.DB.QueryRow(`SELECT role FROM org_members WHERE org_id = ? AND user_id = ?`, orgID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return role, nil
+}
+
+func GetOrgMembers(orgID int64) ([]OrgMember, error) {
+	query := `
+	SELECT m.user_id, u.email, m.role, m.created_at
+	FROM org_members m JOIN users u ON u.id = m.user_id
+	WHERE m.org_id = ?
+	ORDER BY m.created_at`
+
+	rows, err := db.DB.Query(query, orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	members := []OrgMember{}
+
+	for rows.Next() {
+		var m OrgMember
+		err := rows.Scan(&m.UserID, &m.Email, &m.Role, &m.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, nil
+}
+
+func (o Organization) AddMember(userID int64, role string) error {
+	if role != OrgRoleAdmin && role != OrgRoleMember {
+		return ErrInvalidOrgRole
+	}
+
+	query := `
+	INSERT INTO org_members (org_id, user_id, role, created_at) VALUES (?, ?, ?, ?)
+	ON CONFLICT(org_id, user_id) DO NOTHING`
+
+	result, err := db.DB.Exec(query, o.ID, userID, role, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return ErrAlreadyOrgMember
+	}
+
+	return nil
+}
+
+// RemoveMember refuses to remove the last admin, someone has to manage the organization.
+func (o Organization) RemoveMember(userID int64) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var role string
+	err = tx.QueryRow(`SELECT role FROM org_members WHERE org_id = ? AND user_id = ?`, o.ID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrOrgMemberNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	if role == OrgRoleAdmin {
+		var admins int
+		err = tx.QueryRow(`SELECT COUNT(*) FROM org_members WHERE org_id = ? AND role = ?`, o.ID, OrgRoleAdmin).Scan(&admins)
+		if err != nil {
+			return err
+		}
+		if admins <= 1 {
+			return ErrLastOrgAdmin
+		}
+	}
+
+	_, err = tx.Exec(`DELETE FROM org_members WHERE org_id = ? AND user_id = ?`, o.ID, userID)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
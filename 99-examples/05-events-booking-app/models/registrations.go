@@ -1,17 +1,83 @@
 package models
 
 import (
+	"database/sql"
+	"errors"
 	"events-booking/db"
+	"events-booking/jobs"
+	"fmt"
+	"slices"
+	"time"
 )
 
-type Registration struct {
-	ID      int64 `json:"id"`
-	UserID  int64 `json:"user_id"`
+// Registration states
+const (
+	RegistrationPending   = "pending"
+	RegistrationConfirmed = "confirmed"
+	RegistrationCancelled = "cancelled"
+	RegistrationAttended  = "attended"
+	RegistrationNoShow    = "no-show"
+)
+
+// registrationTransitions lists the states each state can move to. A cancelled
+// registration comes back when the user signs up again, attended and no-show
+// can be swapped to correct a check-in mistake.
+var registrationTransitions = map[string][]string{
+	RegistrationPending:   {RegistrationConfirmed, RegistrationCancelled},
+	RegistrationConfirmed: {RegistrationCancelled, RegistrationAttended, RegistrationNoShow},
+	RegistrationCancelled: {RegistrationPending, RegistrationConfirmed},
+	RegistrationAttended:  {RegistrationNoShow},
+	RegistrationNoShow:    {RegistrationAttended},
+}
+
+var (
+	ErrAlreadyRegistered    = errors.New("user is already registered for this event")
+	ErrRegistrationNotFound = errors.New("registration not found")
+	ErrInvalidTransition    = errors.New("invalid registration status change")
+	ErrRegistrationRemoved  = errors.New("registration was cancelled by a host")
+)
+
+// JobRegistrationConfirmation is the job kind queued when a registration gets confirmed.
+const JobRegistrationConfirmation = "registration_confirmation"
+
+type RegistrationJob struct {
 	EventID int64 `json:"event_id"`
+	UserID  int64 `json:"user_id"`
+}
+
+type Registration struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"user_id"`
+	EventID   int64      `json:"event_id"`
+	CreatedAt *time.Time `json:"created_at"` // unknown for sign-ups made before it was tracked
+	Status    string     `json:"status"`
+}
+
+// Attendee is a registration as the event's hosts see it.
+type Attendee struct {
+	UserID       int64      `json:"user_id"`
+	Email        string     `json:"email"`
+	RegisteredAt *time.Time `json:"registered_at"`
+	Status       string     `json:"status"`
+}
+
+// RegistrationEvent is one status change of a registration.
+type RegistrationEvent struct {
+	ID         int64     `json:"id"`
+	FromStatus *string   `json:"from_status"` // nil for the sign-up
+	ToStatus   string    `json:"to_status"`
+	ActorID    *int64    `json:"actor_id"` // nil when nobody made the change by hand
+	Reason     string    `json:"reason"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CanTransition reports whether a registration may move from one status to the other.
+func CanTransition(from string, to string) bool {
+	return slices.Contains(registrationTransitions[from], to)
 }
 
 func GetAllRegistrations() ([]Registration, error) {
-	query := "SELECT * FROM registrations"
+	query := "SELECT id, user_id, event_id, created_at, status FROM registrations"
 
 	rows, err := db.DB.Query(query)
 	if err != nil {
@@ -23,7 +89,7 @@ func GetAllRegistrations() ([]Registration, error) {
 
 	for rows.Next() {
 		var r Registration
-		err := rows.Scan(&r.ID, &r.UserID, &r.EventID)
+		err := rows.Scan(&r.ID, &r.UserID, &r.EventID, &r.CreatedAt, &r.Status)
 		if err != nil {
 			return nil, err
 		}
@@ -32,3 +98,276 @@ func GetAllRegistrations() ([]Registration, error) {
 
 	return regs, nil
 }
+
+// GetRegistration returns ErrRegistrationNotFound if the user never registered for the event.
+// Cancelled registrations are returned too.
+func GetRegistration(eventID int64, userID int64) (*Registration, error) {
+	return getRegistration(db.DB, eventID, userID)
+}
+
+// queryRower is implemented by both *sql.DB and *sql.Tx
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+func getRegistration(q queryRower, eventID int64, userID int64) (*Registration, error) {
+	query := "SELECT id, user_id, event_id, created_at, status FROM registrations WHERE event_id = ? AND user_id = ?"
+
+	var r Registration
+	err := q.QueryRow(query, eventID, userID).Scan(&r.ID, &r.UserID, &r.EventID, &r.CreatedAt, &r.Status)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrRegistrationNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &r, nil
+}
+
+// GetEventAttendees lists the registrations of the event, only the ones with
+// the given status unless it is empty.
+func GetEventAttendees(eventID int64, status string) ([]Attendee, error) {
+	query := `
+	SELECT r.user_id, u.email, r.created_at, r.status
+	FROM registrations r JOIN users u ON u.id = r.user_id
+	WHERE r.event_id = ? AND (? = '' OR r.status = ?)
+	ORDER BY r.created_at, r.id`
+
+	rows, err := db.DB.Query(query, eventID, status, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attendees := []Attendee{}
+
+	for rows.Next() {
+		var a Attendee
+		err := rows.Scan(&a.UserID, &a.Email, &a.RegisteredAt, &a.Status)
+		if err != nil {
+			return nil, err
+		}
+		attendees = append(attendees, a)
+	}
+
+	return attendees, nil
+}
+
+// Register signs the user up, or up again after cancelling. Events requiring
+// approval start the registration as pending, others confirm it right away.
+// Users a host removed or rejected get ErrRegistrationRemoved.
+func (e Event) Register(userId int64) (*Registration, error) {
+	status := RegistrationConfirmed
+	if e.RequiresApproval {
+		status = RegistrationPending
+	}
+
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	now := time.Now().UTC()
+	r, err := getRegistration(tx, e.ID, userId)
+	switch {
+	case errors.Is(err, ErrRegistrationNotFound):
+		r = &Registration{UserID: userId, EventID: e.ID, CreatedAt: &now, Status: status}
+		result, err := tx.Exec(`INSERT INTO registrations (event_id, user_id, created_at, status) VALUES (?, ?, ?, ?)`,
+			e.ID, userId, now, status)
+		if err != nil {
+			return nil, err
+		}
+		r.ID, err = result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		err = recordRegistrationEvent(tx, r.ID, nil, status, &userId, "")
+		if err != nil {
+			return nil, err
+		}
+	case err != nil:
+		return nil, err
+	case r.Status == RegistrationCancelled:
+		err = checkCancelledByUser(tx, r.ID, userId)
+		if err != nil {
+			return nil, err
+		}
+		r, err = transitionRegistration(tx, e.ID, userId, "", status, &userId, "")
+		if err != nil {
+			return nil, err
+		}
+		// the sign-up time is the one of the new sign-up
+		_, err = tx.Exec(`UPDATE registrations SET created_at = ? WHERE id = ?`, now, r.ID)
+		if err != nil {
+			return nil, err
+		}
+		r.CreatedAt = &now
+	default:
+		return nil, ErrAlreadyRegistered
+	}
+
+	if status == RegistrationConfirmed {
+		err = enqueueConfirmation(tx, e.ID, userId)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+	invalidateEventDetail(e.ID)
+
+	return r, nil
+}
+
+// CancelRegistration is used by the user and by hosts removing an attendee.
+// The registration stays, with its history, as cancelled.
+func (e Event) CancelRegistration(userId int64, actorId int64, reason string) error {
+	return e.changeRegistration(userId, "", RegistrationCancelled, actorId, reason)
+}
+
+// ApproveRegistration confirms a pending registration and queues the confirmation.
+func (e Event) ApproveRegistration(userId int64, actorId int64) error {
+	return e.changeRegistration(userId, RegistrationPending, RegistrationConfirmed, actorId, "")
+}
+
+// RejectRegistration cancels a pending registration.
+func (e Event) RejectRegistration(userId int64, actorId int64, reason string) error {
+	return e.changeRegistration(userId, RegistrationPending, RegistrationCancelled, actorId, reason)
+}
+
+// MarkAttended checks a registered user in.
+func (e Event) MarkAttended(userId int64, actorId int64) error {
+	return e.changeRegistration(userId, "", RegistrationAttended, actorId, "")
+}
+
+// MarkNoShow flags a registered user who didn't turn up.
+func (e Event) MarkNoShow(userId int64, actorId int64) error {
+	return e.changeRegistration(userId, "", RegistrationNoShow, actorId, "")
+}
+
+// changeRegistration moves the registration to status to, from must match the
+// current status unless it is empty.
+func (e Event) changeRegistration(userId int64, from string, to string, actorId int64, reason string) error {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = transitionRegistration(tx, e.ID, userId, from, to, &actorId, reason)
+	if err != nil {
+		return err
+	}
+
+	// only approvals end up here, sign-ups queue it in Register
+	if to == RegistrationConfirmed {
+		err = enqueueConfirmation(tx, e.ID, userId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return err
+	}
+	invalidateEventDetail(e.ID)
+
+	return nil
+}
+
+// transitionRegistration changes the status inside tx and records the change,
+// returning ErrInvalidTransition for changes registrationTransitions doesn't allow.
+func transitionRegistration(tx *sql.Tx, eventID int64, userID int64, from string, to string, actorID *int64, reason string) (*Registration, error) {
+	r, err := getRegistration(tx, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if from != "" && r.Status != from || !CanTransition(r.Status, to) {
+		return nil, fmt.Errorf("%w: the registration is %s", ErrInvalidTransition, r.Status)
+	}
+
+	_, err = tx.Exec(`UPDATE registrations SET status = ? WHERE id = ?`, to, r.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = recordRegistrationEvent(tx, r.ID, &r.Status, to, actorID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	r.Status = to
+	return r, nil
+}
+
+// checkCancelledByUser returns ErrRegistrationRemoved unless the last
+// cancellation was made by the user or by nobody in particular.
+func checkCancelledByUser(tx *sql.Tx, registrationID int64, userID int64) error {
+	var actorID sql.NullInt64
+	err := tx.QueryRow(`
+	SELECT actor_id FROM registration_events
+	WHERE registration_id = ? AND to_status = ?
+	ORDER BY id DESC LIMIT 1`, registrationID, RegistrationCancelled).Scan(&actorID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if actorID.Valid && actorID.Int64 != userID {
+		return ErrRegistrationRemoved
+	}
+	return nil
+}
+
+func recordRegistrationEvent(tx *sql.Tx, registrationID int64, from *string, to string, actorID *int64, reason string) error {
+	_, err := tx.Exec(`
+	INSERT INTO registration_events (registration_id, from_status, to_status, actor_id, reason, created_at)
+	VALUES (?, ?, ?, ?, ?, ?)`,
+		registrationID, from, to, actorID, reason, time.Now().UTC())
+	return err
+}
+
+func enqueueConfirmation(tx *sql.Tx, eventID int64, userID int64) error {
+	_, err := jobs.EnqueueTx(tx, jobs.NewJob{
+		Kind:    JobRegistrationConfirmation,
+		Payload: RegistrationJob{EventID: eventID, UserID: userID},
+	})
+	return err
+}
+
+// GetRegistrationHistory lists the status changes of the user's registration, oldest first.
+func GetRegistrationHistory(eventID int64, userID int64) ([]RegistrationEvent, error) {
+	query := `
+	SELECT re.id, re.from_status, re.to_status, re.actor_id, re.reason, re.created_at
+	FROM registration_events re JOIN registrations r ON r.id = re.registration_id
+	WHERE r.event_id = ? AND r.user_id = ?
+	ORDER BY re.id`
+
+	rows, err := db.DB.Query(query, eventID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []RegistrationEvent{}
+
+	for rows.Next() {
+		var h RegistrationEvent
+		err := rows.Scan(&h.ID, &h.FromStatus, &h.ToStatus, &h.ActorID, &h.Reason, &h.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+
+	return history, rows.Err()
+}
@@ -0,0 +1,221 @@
+package models
+
+import (
+	"errors"
+	"events-booking/db"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+)
+
+// TestMain runs the tests against a fresh events.db in a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "models-test")
+	if err != nil {
+		panic(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		panic(err)
+	}
+	db.InitDB()
+
+	code := m.Run()
+	db.DB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+func newTestUser(t *testing.T) int64 {
+	t.Helper()
+
+	result, err := db.DB.Exec(`INSERT INTO users (email, password) VALUES (?, '')`,
+		fmt.Sprintf("%s-%d@example.com", t.Name(), time.Now().UnixNano()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func newTestEvent(t *testing.T, requiresApproval bool) Event {
+	t.Helper()
+
+	e := Event{
+		Name:             "Meetup",
+		Description:      "A meetup",
+		DateTime:         time.Now().Add(24 * time.Hour),
+		Location:         "Room 1",
+		UserID:           newTestUser(t),
+		RequiresApproval: requiresApproval,
+	}
+	err := e.Save()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return e
+}
+
+func countRegistrationEvents(t *testing.T, eventID int64, userID int64) int {
+	t.Helper()
+
+	history, err := GetRegistrationHistory(eventID, userID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(history)
+}
+
+func TestCanTransition(t *testing.T) {
+	tests := []struct {
+		from string
+		to   string
+		want bool
+	}{
+		{RegistrationPending, RegistrationConfirmed, true},
+		{RegistrationPending, RegistrationCancelled, true},
+		{RegistrationPending, RegistrationAttended, false},
+		{RegistrationPending, RegistrationNoShow, false},
+		{RegistrationPending, RegistrationPending, false},
+
+		{RegistrationConfirmed, RegistrationCancelled, true},
+		{RegistrationConfirmed, RegistrationAttended, true},
+		{RegistrationConfirmed, RegistrationNoShow, true},
+		{RegistrationConfirmed, RegistrationPending, false},
+		{RegistrationConfirmed, RegistrationConfirmed, false},
+
+		{RegistrationCancelled, RegistrationPending, true},
+		{RegistrationCancelled, RegistrationConfirmed, true},
+		{RegistrationCancelled, RegistrationAttended, false},
+		{RegistrationCancelled, RegistrationNoShow, false},
+		{RegistrationCancelled, RegistrationCancelled, false},
+
+		{RegistrationAttended, RegistrationNoShow, true},
+		{RegistrationAttended, RegistrationCancelled, false},
+		{RegistrationAttended, RegistrationConfirmed, false},
+
+		{RegistrationNoShow, RegistrationAttended, true},
+		{RegistrationNoShow, RegistrationCancelled, false},
+		{RegistrationNoShow, RegistrationConfirmed, false},
+
+		{"", RegistrationConfirmed, false},
+		{"unknown", RegistrationCancelled, false},
+	}
+
+	for _, tt := range tests {
+		got := CanTransition(tt.from, tt.to)
+		if got != tt.want {
+			t.Errorf("CanTransition(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+// registrationAction is one call on the event by the user or by the event's owner.
+type registrationAction func(e Event, userID int64) error
+
+func TestRegistrationChanges(t *testing.T) {
+	register := func(e Event, userID int64) error {
+		_, err := e.Register(userID)
+		return err
+	}
+	cancel := func(e Event, userID int64) error {
+		return e.CancelRegistration(userID, userID, "")
+	}
+	remove := func(e Event, userID int64) error {
+		return e.CancelRegistration(userID, e.UserID, "")
+	}
+	approve := func(e Event, userID int64) error {
+		return e.ApproveRegistration(userID, e.UserID)
+	}
+	reject := func(e Event, userID int64) error {
+		return e.RejectRegistration(userID, e.UserID, "")
+	}
+	checkIn := func(e Event, userID int64) error {
+		return e.MarkAttended(userID, e.UserID)
+	}
+	noShow := func(e Event, userID int64) error {
+		return e.MarkNoShow(userID, e.UserID)
+	}
+	// no route moves a registration back to pending, the table must refuse it anyway
+	toPending := func(e Event, userID int64) error {
+		return e.changeRegistration(userID, "", RegistrationPending, e.UserID, "")
+	}
+
+	tests := []struct {
+		name       string
+		approval   bool // the event requires approval
+		setup      []registrationAction
+		action     registrationAction
+		wantErr    error
+		wantStatus string
+	}{
+		{"sign-up", false, nil, register, nil, RegistrationConfirmed},
+		{"sign-up needing approval", true, nil, register, nil, RegistrationPending},
+		{"second sign-up", false, []registrationAction{register}, register, ErrAlreadyRegistered, RegistrationConfirmed},
+		{"cancel", false, []registrationAction{register}, cancel, nil, RegistrationCancelled},
+		{"sign up again after cancelling", false, []registrationAction{register, cancel}, register, nil, RegistrationConfirmed},
+		{"approve", true, []registrationAction{register}, approve, nil, RegistrationConfirmed},
+		{"approve twice", true, []registrationAction{register, approve}, approve, ErrInvalidTransition, RegistrationConfirmed},
+		{"reject", true, []registrationAction{register}, reject, nil, RegistrationCancelled},
+		{"reject confirmed", false, []registrationAction{register}, reject, ErrInvalidTransition, RegistrationConfirmed},
+		{"remove", false, []registrationAction{register}, remove, nil, RegistrationCancelled},
+		{"sign up again after being removed", false, []registrationAction{register, remove}, register, ErrRegistrationRemoved, RegistrationCancelled},
+		{"sign up again after being rejected", true, []registrationAction{register, reject}, register, ErrRegistrationRemoved, RegistrationCancelled},
+		{"sign up again after cancelling a sign-up that was approved", true, []registrationAction{register, approve, cancel}, register, nil, RegistrationPending},
+		{"check in", false, []registrationAction{register}, checkIn, nil, RegistrationAttended},
+		{"check in pending", true, []registrationAction{register}, checkIn, ErrInvalidTransition, RegistrationPending},
+		{"check in cancelled", false, []registrationAction{register, cancel}, checkIn, ErrInvalidTransition, RegistrationCancelled},
+		{"no-show", false, []registrationAction{register}, noShow, nil, RegistrationNoShow},
+		{"correct a check-in", false, []registrationAction{register, checkIn}, noShow, nil, RegistrationNoShow},
+		{"correct a no-show", false, []registrationAction{register, noShow}, checkIn, nil, RegistrationAttended},
+		{"cancel after attending", false, []registrationAction{register, checkIn}, cancel, ErrInvalidTransition, RegistrationAttended},
+		{"confirmed back to pending", false, []registrationAction{register}, toPending, ErrInvalidTransition, RegistrationConfirmed},
+		{"cancel without registration", false, nil, cancel, ErrRegistrationNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestEvent(t, tt.approval)
+			userID := newTestUser(t)
+
+			for _, step := range tt.setup {
+				err := step(e, userID)
+				if err != nil {
+					t.Fatalf("setup failed: %v", err)
+				}
+			}
+			before := countRegistrationEvents(t, e.ID, userID)
+
+			err := tt.action(e, userID)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+
+			r, err := GetRegistration(e.ID, userID)
+			switch {
+			case tt.wantStatus == "":
+				if !errors.Is(err, ErrRegistrationNotFound) {
+					t.Fatalf("got registration %+v, %v, want none", r, err)
+				}
+			case err != nil:
+				t.Fatal(err)
+			case r.Status != tt.wantStatus:
+				t.Errorf("status is %s, want %s", r.Status, tt.wantStatus)
+			}
+
+			// every change is recorded, a refused one leaves no trace
+			want := before + 1
+			if tt.wantErr != nil {
+				want = before
+			}
+			got := countRegistrationEvents(t, e.ID, userID)
+			if got != want {
+				t.Errorf("%d registration events, want %d", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+import (
+	"events-booking/db"
+	"time"
+)
+
+// ReminderTarget is one registered user of an upcoming event.
+type ReminderTarget struct {
+	EventID   int64
+	UserID    int64
+	Email     string
+	EventName string
+	Location  string
+	EventDate time.Time
+}
+
+// GetUpcomingRegistrations lists the registrations of events starting in (from, to].
+// Only confirmed registrations count, users who turned reminders off are left out.
+func GetUpcomingRegistrations(from time.Time, to time.Time) ([]ReminderTarget, error) {
+	query := `
+	SELECT r.event_id, r.user_id, u.email, e.name, e.location, e.date
+	FROM registrations r
+	JOIN events e ON e.id = r.event_id
+	JOIN users u ON u.id = r.user_id
+	WHERE e.date > ? AND e.date <= ? AND r.status = ? AND u.notify_reminders = 1 AND u.deleted_at IS NULL`
+
+	rows, err := db.DB.Query(query, from.UTC(), to.UTC(), RegistrationConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var targets []ReminderTarget
+
+	for rows.Next() {
+		var t ReminderTarget
+		err := rows.Scan(&t.EventID, &t.UserID, &t.Email, &t.EventName, &t.Location, &t.EventDate)
+		if err != nil {
+			return nil, err
+		}
+		targets = append(targets, t)
+	}
+
+	return targets, nil
+}
+
+// ClaimReminder records the reminder as sent and reports false if it already was,
+// so a restarted scheduler never sends the same reminder twice.
+func ClaimReminder(eventID int64, userID int64, offset time.Duration) (bool, error) {
+	query := `
+	INSERT OR IGNORE INTO sent_reminders (event_id, user_id, offset_seconds, sent_at)
+	VALUES (?, ?, ?, ?)`
+
+	result, err := db.DB.Exec(query, eventID, userID, int64(offset.Seconds()), time.Now().UTC())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+// ReleaseReminder undoes a claim when sending failed, so the next run retries it.
+func ReleaseReminder(eventID int64, userID int64, offset time.Duration) error {
+	query := `DELETE FROM sent_reminders WHERE event_id = ? AND user_id = ? AND offset_seconds = ?`
+
+	_, err := db.DB.Exec(query, eventID, userID, int64(offset.Seconds()))
+	return err
+}
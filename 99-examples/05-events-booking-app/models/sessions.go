@@ -0,0 +1,227 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/cache"
+	"events-booking/db"
+	"events-booking/utils"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrSessionNotFound = errors.New("session not found")
+	ErrTokenRevoked    = errors.New("token was revoked")
+)
+
+// last_seen_at is written at most this often per session
+const sessionSeenInterval = time.Minute
+
+// user agents are cut to this many bytes, they are only shown to the user
+const maxUserAgentLength = 512
+
+// Session is one login of the user, on one device.
+type Session struct {
+	ID         int64     `json:"id"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"` // the session of the request
+}
+
+// SessionClient describes where a login comes from.
+type SessionClient struct {
+	UserAgent string
+	IP        string
+}
+
+// sessionCache holds the sessions Authenticate has seen, nil when caching is off.
+// Revoking drops the keys, so only other instances see a revocation up to the TTL late.
+var sessionCache *cache.ReadThrough
+
+// SetSessionCache turns on caching of the session lookup of every authenticated request.
+func SetSessionCache(c cache.Cache) {
+	sessionCache = cache.NewReadThrough(c)
+}
+
+// keys start with the user so all sessions of a user can be dropped at once
+func sessionKey(userID int64, sessionID int64) string {
+	return fmt.Sprintf("%s%d", sessionUserPrefix(userID), sessionID)
+}
+
+func sessionUserPrefix(userID int64) string {
+	return fmt.Sprintf("session:%d:", userID)
+}
+
+func invalidateSession(userID int64, sessionID int64) {
+	if sessionCache != nil {
+		sessionCache.Invalidate(sessionKey(userID, sessionID))
+	}
+}
+
+func invalidateUserSessions(userID int64) {
+	if sessionCache != nil {
+		sessionCache.InvalidatePrefix(sessionUserPrefix(userID))
+	}
+}
+
+// activeSession is what the cache keeps, active is false for revoked or unknown sessions.
+type activeSession struct {
+	active     bool
+	lastSeenAt time.Time
+}
+
+// IssueToken starts a session for the user and returns its JWT.
+func IssueToken(userID int64, client SessionClient) (string, error) {
+	var email string
+	err := db.DB.QueryRow(`SELECT email FROM users WHERE id = ? AND deleted_at IS NULL`, userID).Scan(&email)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+
+	// sessions that can't be used anymore are cleaned up on the way
+	_, err = db.DB.Exec(`DELETE FROM sessions WHERE user_id = ? AND (expires_at < ? OR revoked_at IS NOT NULL)`, userID, now)
+	if err != nil {
+		return "", err
+	}
+
+	userAgent := client.UserAgent
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+
+	result, err := db.DB.Exec(`
+	INSERT INTO sessions (user_id, user_agent, ip, created_at, last_seen_at, expires_at) VALUES (?, ?, ?, ?, ?, ?)`,
+		userID, userAgent, client.IP, now, now, now.Add(utils.TokenLifetime))
+	if err != nil {
+		return "", err
+	}
+
+	sessionID, err := result.LastInsertId()
+	if err != nil {
+		return "", err
+	}
+
+	return utils.GenerateJwtToken(email, userID, sessionID)
+}
+
+// AuthenticateToken verifies the JWT and that its session was not revoked,
+// it returns the user and session ids.
+func AuthenticateToken(token string) (int64, int64, error) {
+	claims, err := utils.VerifyJwtToken(token)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	session, err := loadActiveSession(claims.UserID, claims.SessionID)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !session.active {
+		return 0, 0, ErrTokenRevoked
+	}
+
+	now := time.Now().UTC()
+	if now.Sub(session.lastSeenAt) >= sessionSeenInterval {
+		_, err = db.DB.Exec(`UPDATE sessions SET last_seen_at = ? WHERE id = ?`, now, claims.SessionID)
+		if err != nil {
+			return 0, 0, err
+		}
+		invalidateSession(claims.UserID, claims.SessionID)
+	}
+
+	return claims.UserID, claims.SessionID, nil
+}
+
+func loadActiveSession(userID int64, sessionID int64) (activeSession, error) {
+	load := func() (any, error) {
+		var lastSeenAt time.Time
+		err := db.DB.QueryRow(`
+		SELECT s.last_seen_at FROM sessions s JOIN users u ON u.id = s.user_id
+		WHERE s.id = ? AND s.user_id = ? AND s.revoked_at IS NULL AND u.deleted_at IS NULL`,
+			sessionID, userID).Scan(&lastSeenAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return activeSession{}, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return activeSession{active: true, lastSeenAt: lastSeenAt}, nil
+	}
+
+	if sessionCache == nil {
+		value, err := load()
+		if err != nil {
+			return activeSession{}, err
+		}
+		return value.(activeSession), nil
+	}
+
+	value, err := sessionCache.Get(sessionKey(userID, sessionID), load)
+	if err != nil {
+		return activeSession{}, err
+	}
+	return value.(activeSession), nil
+}
+
+// GetUserSessions lists the sessions that can still be used, the newest first.
+func GetUserSessions(userID int64, currentSessionID int64) ([]Session, error) {
+	rows, err := db.DB.Query(`
+	SELECT id, user_agent, ip, created_at, last_seen_at, expires_at FROM sessions
+	WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+	ORDER BY id DESC`, userID, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []Session{}
+	for rows.Next() {
+		var s Session
+		err := rows.Scan(&s.ID, &s.UserAgent, &s.IP, &s.CreatedAt, &s.LastSeenAt, &s.ExpiresAt)
+		if err != nil {
+			return nil, err
+		}
+		s.Current = s.ID == currentSessionID
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeSession logs one of the user's sessions out.
+func RevokeSession(userID int64, sessionID int64) error {
+	result, err := db.DB.Exec(`UPDATE sessions SET revoked_at = ? WHERE id = ? AND user_id = ? AND revoked_at IS NULL`,
+		time.Now().UTC(), sessionID, userID)
+	if err != nil {
+		return err
+	}
+
+	updated, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrSessionNotFound
+	}
+
+	invalidateSession(userID, sessionID)
+	return nil
+}
+
+// RevokeOtherSessions logs out every session of the user but keep, 0 logs out everywhere.
+func RevokeOtherSessions(userID int64, keep int64) error {
+	_, err := db.DB.Exec(`UPDATE sessions SET revoked_at = ? WHERE user_id = ? AND id != ? AND revoked_at IS NULL`,
+		time.Now().UTC(), userID, keep)
+	if err != nil {
+		return err
+	}
+
+	invalidateUserSessions(userID)
+	return nil
+}
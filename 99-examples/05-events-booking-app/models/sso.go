@@ -0,0 +1,99 @@
+package models
+
+import (
+	"database/sql"
+	"errors"
+	"events-booking/db"
+	"events-booking/sso"
+	"time"
+)
+
+var (
+	ErrOIDCLoginNotFound = errors.New("sso login not found or expired")
+	ErrEmailNotVerified  = errors.New("the provider has not verified the email")
+)
+
+func SaveOIDCLogin(login sso.Login, ttl time.Duration) error {
+	now := time.Now()
+
+	// expired logins of abandoned attempts are cleaned up on the way
+	_, err := db.DB.Exec(`DELETE FROM oidc_logins WHERE expires_at < ?`, now.UnixMilli())
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DB.Exec(`INSERT INTO oidc_logins (state, nonce, code_verifier, expires_at) VALUES (?, ?, ?, ?)`,
+		login.State, login.Nonce, login.CodeVerifier, now.Add(ttl).UnixMilli())
+	return err
+}
+
+// TakeOIDCLogin returns the login of the state and deletes it, so a callback can't be replayed.
+func TakeOIDCLogin(state string) (*sso.Login, error) {
+	login := sso.Login{State: state}
+
+	err := db.DB.QueryRow(`DELETE FROM oidc_logins WHERE state = ? AND expires_at >= ? RETURNING nonce, code_verifier`,
+		state, time.Now().UnixMilli()).Scan(&login.Nonce, &login.CodeVerifier)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrOIDCLoginNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &login, nil
+}
+
+// ProvisionOIDCUser finds the user of the identity. The first login links it to
+// the user with the same email, or creates a user without a password (just in
+// time provisioning); both need an email the provider has verified.
+func ProvisionOIDCUser(identity sso.Identity) (*User, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var user User
+	err = tx.QueryRow(`
+	SELECT u.id, u.email FROM user_identities i JOIN users u ON u.id = i.user_id
+	WHERE i.issuer = ? AND i.subject = ?`, identity.Issuer, identity.Subject).Scan(&user.Id, &user.Email)
+	if err == nil {
+		return &user, nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	if !identity.EmailVerified || identity.Email == "" {
+		return nil, ErrEmailNotVerified
+	}
+
+	err = tx.QueryRow(`SELECT id, email FROM users WHERE email = ? COLLATE NOCASE`, identity.Email).Scan(&user.Id, &user.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		// an empty password hash never matches, SSO users can't log in with a password
+		result, err := tx.Exec(`INSERT INTO users (email, password) VALUES (?, '')`, identity.Email)
+		if err != nil {
+			return nil, err
+		}
+		user.Id, err = result.LastInsertId()
+		if err != nil {
+			return nil, err
+		}
+		user.Email = identity.Email
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`INSERT INTO user_identities (issuer, subject, user_id, created_at) VALUES (?, ?, ?, ?)`,
+		identity.Issuer, identity.Subject, user.Id, time.Now().UTC())
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
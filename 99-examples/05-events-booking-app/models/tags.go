@@ -0,0 +1,170 @@
+package models
+
+import (
+	"database/sql"
+	"events-booking/db"
+	"strings"
+)
+
+type FacetCount struct {
+	Slug  string `json:"slug,omitempty"`
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type EventFacets struct {
+	Categories []FacetCount `json:"categories"`
+	Tags       []FacetCount `json:"tags"`
+}
+
+// normalizeTags lower-cases and trims tags, dropping empty and duplicate ones.
+func normalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	normalized := []string{}
+
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	return normalized
+}
+
+// setEventTags replaces the event's tags, creating tags that don't exist yet.
+func setEventTags(tx *sql.Tx, eventID int64, tags []string) error {
+	_, err := tx.Exec(`DELETE FROM event_tags WHERE event_id = ?`, eventID)
+	if err != nil {
+		return err
+	}
+
+	for _, tag := range tags {
+		_, err = tx.Exec(`INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+		INSERT INTO event_tags (event_id, tag_id)
+		SELECT ?, id FROM tags WHERE name = ?`, eventID, tag)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getEventTags loads the tags of several events with one query.
+// Every requested event gets an entry, possibly an empty one.
+func getEventTags(eventIDs []int64) (map[int64][]string, error) {
+	tags := map[int64][]string{}
+	if len(eventIDs) == 0 {
+		return tags, nil
+	}
+
+	args := make([]any, len(eventIDs))
+	for i, id := range eventIDs {
+		args[i] = id
+		tags[id] = []string{}
+	}
+
+	query := `
+	SELECT et.event_id, t.name
+	FROM event_tags et JOIN tags t ON t.id = et.tag_id
+	WHERE et.event_id IN (?` + strings.Repeat(", ?", len(eventIDs)-1) + `)
+	ORDER BY t.name`
+
+	rows, err := db.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var eventID int64
+		var name string
+		err := rows.Scan(&eventID, &name)
+		if err != nil {
+			return nil, err
+		}
+		tags[eventID] = append(tags[eventID], name)
+	}
+
+	return tags, nil
+}
+
+func attachTags(events []Event) error {
+	ids := make([]int64, len(events))
+	for i, e := range events {
+		ids[i] = e.ID
+	}
+
+	tags, err := getEventTags(ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range events {
+		events[i].Tags = tags[events[i].ID]
+	}
+
+	return nil
+}
+
+// GetEventFacets counts the events per category and per tag among the events matching the filter.
+func GetEventFacets(filter EventFilter) (*EventFacets, error) {
+	where, args := filter.where()
+	facets := EventFacets{Categories: []FacetCount{}, Tags: []FacetCount{}}
+
+	categoriesQuery := `
+	SELECT c.slug, c.name, COUNT(e.id)
+	FROM events e JOIN categories c ON c.id = e.category_id
+	WHERE ` + where + `
+	GROUP BY c.id
+	ORDER BY COUNT(e.id) DESC, c.name`
+
+	rows, err := db.DB.Query(categoriesQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var f FacetCount
+		err := rows.Scan(&f.Slug, &f.Name, &f.Count)
+		if err != nil {
+			return nil, err
+		}
+		facets.Categories = append(facets.Categories, f)
+	}
+
+	tagsQuery := `
+	SELECT t.name, COUNT(e.id)
+	FROM events e
+	JOIN event_tags et ON et.event_id = e.id
+	JOIN tags t ON t.id = et.tag_id
+	WHERE ` + where + `
+	GROUP BY t.id
+	ORDER BY COUNT(e.id) DESC, t.name`
+
+	tagRows, err := db.DB.Query(tagsQuery, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer tagRows.Close()
+
+	for tagRows.Next() {
+		var f FacetCount
+		err := tagRows.Scan(&f.Name, &f.Count)
+		if err != nil {
+			return nil, err
+		}
+		facets.Tags = append(facets.Tags, f)
+	}
+
+	return &facets, nil
+}
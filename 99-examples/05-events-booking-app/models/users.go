@@ -4,17 +4,24 @@ import (
 	"errors"
 	"events-booking/db"
 	"events-booking/utils"
+	"log"
 )
 
 type User struct {
 	Id       int64  `json:"id"`
 	Email    string `json:"email" binding:"required"`
-	Password string `json:"password" binding:"required"`
+	Password string `json:"password,omitempty" binding:"required"` // from the request, never sent back
 }
 
+// Save creates the user, the password has to pass utils.ValidatePassword.
 func (u *User) Save() error {
 	query := "INSERT INTO users(email, password) VALUES (?,?)"
 
+	err := utils.ValidatePassword(u.Password, u.Email)
+	if err != nil {
+		return err
+	}
+
 	stmt, err := db.DB.Prepare(query)
 	if err != nil {
 		return err
@@ -53,11 +60,32 @@ func (user *User) ValidateCredentials() error {
 	}
 
 	user.Id = fetchedUser.Id
+
+	// the password is only known now, so older or weaker hashes are upgraded here
+	if utils.PasswordNeedsRehash(fetchedUser.Password) {
+		err = rehashPassword(user.Id, user.Password, fetchedUser.Password)
+		if err != nil {
+			log.Printf("Could not upgrade the password hash of user %d: %v", user.Id, err)
+		}
+	}
+
 	return nil
 }
 
+// rehashPassword stores a new hash unless the password was changed in the meantime.
+func rehashPassword(userID int64, password string, oldHash string) error {
+	hashedPassword, err := utils.HashNewPassword(password)
+	if err != nil {
+		return err
+	}
+
+	_, err = db.DB.Exec("UPDATE users SET password = ? WHERE id = ? AND password = ?", hashedPassword, userID, oldHash)
+	return err
+}
+
+// GetAllUsers loads the users without their password hashes.
 func GetAllUsers() ([]User, error) {
-	query := "SELECT id, email, password FROM users"
+	query := "SELECT id, email FROM users"
 
 	rows, err := db.DB.Query(query)
 	if err != nil {
@@ -70,7 +98,7 @@ func GetAllUsers() ([]User, error) {
 
 	for rows.Next() {
 		var u User
-		err := rows.Scan(&u.Id, &u.Email, &u.Password)
+		err := rows.Scan(&u.Id, &u.Email)
 		if err != nil {
 			return nil, err
 		}
@@ -79,3 +107,42 @@ func GetAllUsers() ([]User, error) {
 
 	return usersList, nil
 }
+
+// GetUserByID loads the user without the password hash.
+func GetUserByID(id int64) (*User, error) {
+	query := "SELECT id, email FROM users WHERE id = ?"
+
+	var u User
+	err := db.DB.QueryRow(query, id).Scan(&u.Id, &u.Email)
+	if err != nil {
+		return nil, err
+	}
+
+	return &u, nil
+}
+
+func GetUserIDByEmail(email string) (int64, error) {
+	query := "SELECT id FROM users WHERE email = ?"
+
+	var id int64
+	err := db.DB.QueryRow(query, email).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// IsAdmin reports whether the user may manage site-wide data such as categories.
+// Admins are flagged directly in the DB (users.is_admin = 1).
+func IsAdmin(userId int64) (bool, error) {
+	query := "SELECT is_admin FROM users WHERE id = ?"
+
+	var isAdmin bool
+	err := db.DB.QueryRow(query, userId).Scan(&isAdmin)
+	if err != nil {
+		return false, err
+	}
+
+	return isAdmin, nil
+}
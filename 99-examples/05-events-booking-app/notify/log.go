@@ -0,0 +1,14 @@
+package notify
+
+import (
+	"context"
+	"log"
+)
+
+// LogNotifier only writes the notification to the app log, handy for development.
+type LogNotifier struct{}
+
+func (LogNotifier) Notify(ctx context.Context, n Notification) error {
+	log.Printf("[notify] %s to %s (user %d): %s", n.Kind, n.Email, n.UserID, n.Subject)
+	return nil
+}
@@ -0,0 +1,39 @@
+// Package notify delivers messages to users through a pluggable channel.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+type Notification struct {
+	Kind    string    `json:"kind"`
+	UserID  int64     `json:"user_id"`
+	Email   string    `json:"email"`
+	EventID int64     `json:"event_id,omitempty"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body"`
+	SentAt  time.Time `json:"sent_at"`
+}
+
+type Notifier interface {
+	Notify(ctx context.Context, n Notification) error
+}
+
+// New picks the notifier by name: log, file or webhook.
+func New(kind string, filePath string, webhookURL string) (Notifier, error) {
+	switch kind {
+	case "log":
+		return LogNotifier{}, nil
+	case "file":
+		return NewFileNotifier(filePath), nil
+	case "webhook":
+		if webhookURL == "" {
+			return nil, fmt.Errorf("webhook notifier needs a URL")
+		}
+		return NewWebhookNotifier(webhookURL), nil
+	}
+
+	return nil, fmt.Errorf("unknown notifier %q", kind)
+}
@@ -0,0 +1,853 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: eventsbooking/v1/events.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type EventChange_Kind int32
+
+const (
+	EventChange_KIND_UNSPECIFIED EventChange_Kind = 0
+	EventChange_KIND_CREATED     EventChange_Kind = 1
+	EventChange_KIND_UPDATED     EventChange_Kind = 2
+	EventChange_KIND_DELETED     EventChange_Kind = 3
+)
+
+// Enum value maps for EventChange_Kind.
+var (
+	EventChange_Kind_name = map[int32]string{
+		0: "KIND_UNSPECIFIED",
+		1: "KIND_CREATED",
+		2: "KIND_UPDATED",
+		3: "KIND_DELETED",
+	}
+	EventChange_Kind_value = map[string]int32{
+		"KIND_UNSPECIFIED": 0,
+		"KIND_CREATED":     1,
+		"KIND_UPDATED":     2,
+		"KIND_DELETED":     3,
+	}
+)
+
+func (x EventChange_Kind) Enum() *EventChange_Kind {
+	p := new(EventChange_Kind)
+	*p = x
+	return p
+}
+
+func (x EventChange_Kind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EventChange_Kind) Descriptor() protoreflect.EnumDescriptor {
+	return file_eventsbooking_v1_events_proto_enumTypes[0].Descriptor()
+}
+
+func (EventChange_Kind) Type() protoreflect.EnumType {
+	return &file_eventsbooking_v1_events_proto_enumTypes[0]
+}
+
+func (x EventChange_Kind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EventChange_Kind.Descriptor instead.
+func (EventChange_Kind) EnumDescriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{10, 0}
+}
+
+type Event struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description   string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Date          *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=date,proto3" json:"date,omitempty"`
+	Location      string                 `protobuf:"bytes,5,opt,name=location,proto3" json:"location,omitempty"`
+	UserId        int64                  `protobuf:"varint,6,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Version       int64                  `protobuf:"varint,7,opt,name=version,proto3" json:"version,omitempty"`
+	CategoryId    *int64                 `protobuf:"varint,8,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	Tags          []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	OrgId         *int64                 `protobuf:"varint,10,opt,name=org_id,json=orgId,proto3,oneof" json:"org_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Event) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Event) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Event) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *Event) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *Event) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Event) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *Event) GetCategoryId() int64 {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return 0
+}
+
+func (x *Event) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Event) GetOrgId() int64 {
+	if x != nil && x.OrgId != nil {
+		return *x.OrgId
+	}
+	return 0
+}
+
+type EventInput struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Name        string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Date        *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=date,proto3" json:"date,omitempty"`
+	Location    string                 `protobuf:"bytes,4,opt,name=location,proto3" json:"location,omitempty"`
+	CategoryId  *int64                 `protobuf:"varint,5,opt,name=category_id,json=categoryId,proto3,oneof" json:"category_id,omitempty"`
+	Tags        []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	// false keeps the current tags on update, true replaces them with tags (possibly none)
+	ReplaceTags   bool `protobuf:"varint,7,opt,name=replace_tags,json=replaceTags,proto3" json:"replace_tags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventInput) Reset() {
+	*x = EventInput{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventInput) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventInput) ProtoMessage() {}
+
+func (x *EventInput) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventInput.ProtoReflect.Descriptor instead.
+func (*EventInput) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *EventInput) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *EventInput) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *EventInput) GetDate() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Date
+	}
+	return nil
+}
+
+func (x *EventInput) GetLocation() string {
+	if x != nil {
+		return x.Location
+	}
+	return ""
+}
+
+func (x *EventInput) GetCategoryId() int64 {
+	if x != nil && x.CategoryId != nil {
+		return *x.CategoryId
+	}
+	return 0
+}
+
+func (x *EventInput) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *EventInput) GetReplaceTags() bool {
+	if x != nil {
+		return x.ReplaceTags
+	}
+	return false
+}
+
+type ListEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Category      string                 `protobuf:"bytes,1,opt,name=category,proto3" json:"category,omitempty"` // category slug
+	Tags          []string               `protobuf:"bytes,2,rep,name=tags,proto3" json:"tags,omitempty"`         // events having all of these tags
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventsRequest) Reset() {
+	*x = ListEventsRequest{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventsRequest) ProtoMessage() {}
+
+func (x *ListEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventsRequest.ProtoReflect.Descriptor instead.
+func (*ListEventsRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListEventsRequest) GetCategory() string {
+	if x != nil {
+		return x.Category
+	}
+	return ""
+}
+
+func (x *ListEventsRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type ListEventsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Events        []*Event               `protobuf:"bytes,1,rep,name=events,proto3" json:"events,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventsResponse) Reset() {
+	*x = ListEventsResponse{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventsResponse) ProtoMessage() {}
+
+func (x *ListEventsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventsResponse.ProtoReflect.Descriptor instead.
+func (*ListEventsResponse) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListEventsResponse) GetEvents() []*Event {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+type GetEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetEventRequest) Reset() {
+	*x = GetEventRequest{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetEventRequest) ProtoMessage() {}
+
+func (x *GetEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetEventRequest.ProtoReflect.Descriptor instead.
+func (*GetEventRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetEventRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CreateEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Event         *EventInput            `protobuf:"bytes,1,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateEventRequest) Reset() {
+	*x = CreateEventRequest{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateEventRequest) ProtoMessage() {}
+
+func (x *CreateEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateEventRequest.ProtoReflect.Descriptor instead.
+func (*CreateEventRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CreateEventRequest) GetEvent() *EventInput {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type UpdateEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"` // the version the change is based on
+	Event         *EventInput            `protobuf:"bytes,3,opt,name=event,proto3" json:"event,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateEventRequest) Reset() {
+	*x = UpdateEventRequest{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateEventRequest) ProtoMessage() {}
+
+func (x *UpdateEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateEventRequest.ProtoReflect.Descriptor instead.
+func (*UpdateEventRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *UpdateEventRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateEventRequest) GetEvent() *EventInput {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+type DeleteEventRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Version       int64                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEventRequest) Reset() {
+	*x = DeleteEventRequest{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEventRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEventRequest) ProtoMessage() {}
+
+func (x *DeleteEventRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEventRequest.ProtoReflect.Descriptor instead.
+func (*DeleteEventRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *DeleteEventRequest) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *DeleteEventRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+type DeleteEventResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteEventResponse) Reset() {
+	*x = DeleteEventResponse{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteEventResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteEventResponse) ProtoMessage() {}
+
+func (x *DeleteEventResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteEventResponse.ProtoReflect.Descriptor instead.
+func (*DeleteEventResponse) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{8}
+}
+
+type WatchEventsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchEventsRequest) Reset() {
+	*x = WatchEventsRequest{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchEventsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchEventsRequest) ProtoMessage() {}
+
+func (x *WatchEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchEventsRequest.ProtoReflect.Descriptor instead.
+func (*WatchEventsRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{9}
+}
+
+type EventChange struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          EventChange_Kind       `protobuf:"varint,1,opt,name=kind,proto3,enum=eventsbooking.v1.EventChange_Kind" json:"kind,omitempty"`
+	Event         *Event                 `protobuf:"bytes,2,opt,name=event,proto3" json:"event,omitempty"` // for deletes, the event as it was
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EventChange) Reset() {
+	*x = EventChange{}
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EventChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EventChange) ProtoMessage() {}
+
+func (x *EventChange) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_events_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EventChange.ProtoReflect.Descriptor instead.
+func (*EventChange) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_events_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *EventChange) GetKind() EventChange_Kind {
+	if x != nil {
+		return x.Kind
+	}
+	return EventChange_KIND_UNSPECIFIED
+}
+
+func (x *EventChange) GetEvent() *Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+var File_eventsbooking_v1_events_proto protoreflect.FileDescriptor
+
+const file_eventsbooking_v1_events_proto_rawDesc = "" +
+	"\n" +
+	"\x1deventsbooking/v1/events.proto\x12\x10eventsbooking.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbd\x02\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12.\n" +
+	"\x04date\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x1a\n" +
+	"\blocation\x18\x05 \x01(\tR\blocation\x12\x17\n" +
+	"\auser_id\x18\x06 \x01(\x03R\x06userId\x12\x18\n" +
+	"\aversion\x18\a \x01(\x03R\aversion\x12$\n" +
+	"\vcategory_id\x18\b \x01(\x03H\x00R\n" +
+	"categoryId\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\x12\x1a\n" +
+	"\x06org_id\x18\n" +
+	" \x01(\x03H\x01R\x05orgId\x88\x01\x01B\x0e\n" +
+	"\f_category_idB\t\n" +
+	"\a_org_id\"\xfb\x01\n" +
+	"\n" +
+	"EventInput\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12.\n" +
+	"\x04date\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04date\x12\x1a\n" +
+	"\blocation\x18\x04 \x01(\tR\blocation\x12$\n" +
+	"\vcategory_id\x18\x05 \x01(\x03H\x00R\n" +
+	"categoryId\x88\x01\x01\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12!\n" +
+	"\freplace_tags\x18\a \x01(\bR\vreplaceTagsB\x0e\n" +
+	"\f_category_id\"C\n" +
+	"\x11ListEventsRequest\x12\x1a\n" +
+	"\bcategory\x18\x01 \x01(\tR\bcategory\x12\x12\n" +
+	"\x04tags\x18\x02 \x03(\tR\x04tags\"E\n" +
+	"\x12ListEventsResponse\x12/\n" +
+	"\x06events\x18\x01 \x03(\v2\x17.eventsbooking.v1.EventR\x06events\"!\n" +
+	"\x0fGetEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\"H\n" +
+	"\x12CreateEventRequest\x122\n" +
+	"\x05event\x18\x01 \x01(\v2\x1c.eventsbooking.v1.EventInputR\x05event\"r\n" +
+	"\x12UpdateEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\x122\n" +
+	"\x05event\x18\x03 \x01(\v2\x1c.eventsbooking.v1.EventInputR\x05event\">\n" +
+	"\x12DeleteEventRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x03R\aversion\"\x15\n" +
+	"\x13DeleteEventResponse\"\x14\n" +
+	"\x12WatchEventsRequest\"\xc8\x01\n" +
+	"\vEventChange\x126\n" +
+	"\x04kind\x18\x01 \x01(\x0e2\".eventsbooking.v1.EventChange.KindR\x04kind\x12-\n" +
+	"\x05event\x18\x02 \x01(\v2\x17.eventsbooking.v1.EventR\x05event\"R\n" +
+	"\x04Kind\x12\x14\n" +
+	"\x10KIND_UNSPECIFIED\x10\x00\x12\x10\n" +
+	"\fKIND_CREATED\x10\x01\x12\x10\n" +
+	"\fKIND_UPDATED\x10\x02\x12\x10\n" +
+	"\fKIND_DELETED\x10\x032\xf7\x03\n" +
+	"\x06Events\x12W\n" +
+	"\n" +
+	"ListEvents\x12#.eventsbooking.v1.ListEventsRequest\x1a$.eventsbooking.v1.ListEventsResponse\x12F\n" +
+	"\bGetEvent\x12!.eventsbooking.v1.GetEventRequest\x1a\x17.eventsbooking.v1.Event\x12L\n" +
+	"\vCreateEvent\x12$.eventsbooking.v1.CreateEventRequest\x1a\x17.eventsbooking.v1.Event\x12L\n" +
+	"\vUpdateEvent\x12$.eventsbooking.v1.UpdateEventRequest\x1a\x17.eventsbooking.v1.Event\x12Z\n" +
+	"\vDeleteEvent\x12$.eventsbooking.v1.DeleteEventRequest\x1a%.eventsbooking.v1.DeleteEventResponse\x12T\n" +
+	"\vWatchEvents\x12$.eventsbooking.v1.WatchEventsRequest\x1a\x1d.eventsbooking.v1.EventChange0\x01B\x16Z\x14events-booking/pb;pbb\x06proto3"
+
+var (
+	file_eventsbooking_v1_events_proto_rawDescOnce sync.Once
+	file_eventsbooking_v1_events_proto_rawDescData []byte
+)
+
+func file_eventsbooking_v1_events_proto_rawDescGZIP() []byte {
+	file_eventsbooking_v1_events_proto_rawDescOnce.Do(func() {
+		file_eventsbooking_v1_events_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_eventsbooking_v1_events_proto_rawDesc), len(file_eventsbooking_v1_events_proto_rawDesc)))
+	})
+	return file_eventsbooking_v1_events_proto_rawDescData
+}
+
+var file_eventsbooking_v1_events_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_eventsbooking_v1_events_proto_msgTypes = make([]protoimpl.MessageInfo, 11)
+var file_eventsbooking_v1_events_proto_goTypes = []any{
+	(EventChange_Kind)(0),         // 0: eventsbooking.v1.EventChange.Kind
+	(*Event)(nil),                 // 1: eventsbooking.v1.Event
+	(*EventInput)(nil),            // 2: eventsbooking.v1.EventInput
+	(*ListEventsRequest)(nil),     // 3: eventsbooking.v1.ListEventsRequest
+	(*ListEventsResponse)(nil),    // 4: eventsbooking.v1.ListEventsResponse
+	(*GetEventRequest)(nil),       // 5: eventsbooking.v1.GetEventRequest
+	(*CreateEventRequest)(nil),    // 6: eventsbooking.v1.CreateEventRequest
+	(*UpdateEventRequest)(nil),    // 7: eventsbooking.v1.UpdateEventRequest
+	(*DeleteEventRequest)(nil),    // 8: eventsbooking.v1.DeleteEventRequest
+	(*DeleteEventResponse)(nil),   // 9: eventsbooking.v1.DeleteEventResponse
+	(*WatchEventsRequest)(nil),    // 10: eventsbooking.v1.WatchEventsRequest
+	(*EventChange)(nil),           // 11: eventsbooking.v1.EventChange
+	(*timestamppb.Timestamp)(nil), // 12: google.protobuf.Timestamp
+}
+var file_eventsbooking_v1_events_proto_depIdxs = []int32{
+	12, // 0: eventsbooking.v1.Event.date:type_name -> google.protobuf.Timestamp
+	12, // 1: eventsbooking.v1.EventInput.date:type_name -> google.protobuf.Timestamp
+	1,  // 2: eventsbooking.v1.ListEventsResponse.events:type_name -> eventsbooking.v1.Event
+	2,  // 3: eventsbooking.v1.CreateEventRequest.event:type_name -> eventsbooking.v1.EventInput
+	2,  // 4: eventsbooking.v1.UpdateEventRequest.event:type_name -> eventsbooking.v1.EventInput
+	0,  // 5: eventsbooking.v1.EventChange.kind:type_name -> eventsbooking.v1.EventChange.Kind
+	1,  // 6: eventsbooking.v1.EventChange.event:type_name -> eventsbooking.v1.Event
+	3,  // 7: eventsbooking.v1.Events.ListEvents:input_type -> eventsbooking.v1.ListEventsRequest
+	5,  // 8: eventsbooking.v1.Events.GetEvent:input_type -> eventsbooking.v1.GetEventRequest
+	6,  // 9: eventsbooking.v1.Events.CreateEvent:input_type -> eventsbooking.v1.CreateEventRequest
+	7,  // 10: eventsbooking.v1.Events.UpdateEvent:input_type -> eventsbooking.v1.UpdateEventRequest
+	8,  // 11: eventsbooking.v1.Events.DeleteEvent:input_type -> eventsbooking.v1.DeleteEventRequest
+	10, // 12: eventsbooking.v1.Events.WatchEvents:input_type -> eventsbooking.v1.WatchEventsRequest
+	4,  // 13: eventsbooking.v1.Events.ListEvents:output_type -> eventsbooking.v1.ListEventsResponse
+	1,  // 14: eventsbooking.v1.Events.GetEvent:output_type -> eventsbooking.v1.Event
+	1,  // 15: eventsbooking.v1.Events.CreateEvent:output_type -> eventsbooking.v1.Event
+	1,  // 16: eventsbooking.v1.Events.UpdateEvent:output_type -> eventsbooking.v1.Event
+	9,  // 17: eventsbooking.v1.Events.DeleteEvent:output_type -> eventsbooking.v1.DeleteEventResponse
+	11, // 18: eventsbooking.v1.Events.WatchEvents:output_type -> eventsbooking.v1.EventChange
+	13, // [13:19] is the sub-list for method output_type
+	7,  // [7:13] is the sub-list for method input_type
+	7,  // [7:7] is the sub-list for extension type_name
+	7,  // [7:7] is the sub-list for extension extendee
+	0,  // [0:7] is the sub-list for field type_name
+}
+
+func init() { file_eventsbooking_v1_events_proto_init() }
+func file_eventsbooking_v1_events_proto_init() {
+	if File_eventsbooking_v1_events_proto != nil {
+		return
+	}
+	file_eventsbooking_v1_events_proto_msgTypes[0].OneofWrappers = []any{}
+	file_eventsbooking_v1_events_proto_msgTypes[1].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_eventsbooking_v1_events_proto_rawDesc), len(file_eventsbooking_v1_events_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   11,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_eventsbooking_v1_events_proto_goTypes,
+		DependencyIndexes: file_eventsbooking_v1_events_proto_depIdxs,
+		EnumInfos:         file_eventsbooking_v1_events_proto_enumTypes,
+		MessageInfos:      file_eventsbooking_v1_events_proto_msgTypes,
+	}.Build()
+	File_eventsbooking_v1_events_proto = out.File
+	file_eventsbooking_v1_events_proto_goTypes = nil
+	file_eventsbooking_v1_events_proto_depIdxs = nil
+}
@@ -0,0 +1,4 @@
+// Package pb holds the code generated from proto/eventsbooking/v1, don't edit the *.pb.go files.
+package pb
+
+//go:generate protoc -I ../proto --go_out=. --go_opt=module=events-booking/pb --go-grpc_out=. --go-grpc_opt=module=events-booking/pb ../proto/eventsbooking/v1/events.proto ../proto/eventsbooking/v1/users.proto ../proto/eventsbooking/v1/registrations.proto
@@ -0,0 +1,440 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: eventsbooking/v1/registrations.proto
+
+package pb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Registration struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int64                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	UserId        int64                  `protobuf:"varint,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	EventId       int64                  `protobuf:"varint,3,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // unset for sign-ups made before it was tracked
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Registration) Reset() {
+	*x = Registration{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Registration) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Registration) ProtoMessage() {}
+
+func (x *Registration) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Registration.ProtoReflect.Descriptor instead.
+func (*Registration) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Registration) GetId() int64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Registration) GetUserId() int64 {
+	if x != nil {
+		return x.UserId
+	}
+	return 0
+}
+
+func (x *Registration) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *Registration) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Registration) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type RegisterRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RegisterRequest) Reset() {
+	*x = RegisterRequest{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RegisterRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RegisterRequest) ProtoMessage() {}
+
+func (x *RegisterRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RegisterRequest.ProtoReflect.Descriptor instead.
+func (*RegisterRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RegisterRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+type CancelRegistrationRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRegistrationRequest) Reset() {
+	*x = CancelRegistrationRequest{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRegistrationRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRegistrationRequest) ProtoMessage() {}
+
+func (x *CancelRegistrationRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRegistrationRequest.ProtoReflect.Descriptor instead.
+func (*CancelRegistrationRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CancelRegistrationRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+type CancelRegistrationResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelRegistrationResponse) Reset() {
+	*x = CancelRegistrationResponse{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelRegistrationResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelRegistrationResponse) ProtoMessage() {}
+
+func (x *CancelRegistrationResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelRegistrationResponse.ProtoReflect.Descriptor instead.
+func (*CancelRegistrationResponse) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{3}
+}
+
+type ListMyRegistrationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyRegistrationsRequest) Reset() {
+	*x = ListMyRegistrationsRequest{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyRegistrationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyRegistrationsRequest) ProtoMessage() {}
+
+func (x *ListMyRegistrationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyRegistrationsRequest.ProtoReflect.Descriptor instead.
+func (*ListMyRegistrationsRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{4}
+}
+
+type ListEventRegistrationsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       int64                  `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListEventRegistrationsRequest) Reset() {
+	*x = ListEventRegistrationsRequest{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListEventRegistrationsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListEventRegistrationsRequest) ProtoMessage() {}
+
+func (x *ListEventRegistrationsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListEventRegistrationsRequest.ProtoReflect.Descriptor instead.
+func (*ListEventRegistrationsRequest) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListEventRegistrationsRequest) GetEventId() int64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+type ListRegistrationsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Registrations []*Registration        `protobuf:"bytes,1,rep,name=registrations,proto3" json:"registrations,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRegistrationsResponse) Reset() {
+	*x = ListRegistrationsResponse{}
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRegistrationsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRegistrationsResponse) ProtoMessage() {}
+
+func (x *ListRegistrationsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_eventsbooking_v1_registrations_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRegistrationsResponse.ProtoReflect.Descriptor instead.
+func (*ListRegistrationsResponse) Descriptor() ([]byte, []int) {
+	return file_eventsbooking_v1_registrations_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListRegistrationsResponse) GetRegistrations() []*Registration {
+	if x != nil {
+		return x.Registrations
+	}
+	return nil
+}
+
+var File_eventsbooking_v1_registrations_proto protoreflect.FileDescriptor
+
+const file_eventsbooking_v1_registrations_proto_rawDesc = "" +
+	"\n" +
+	"$eventsbooking/v1/registrations.proto\x12\x10eventsbooking.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xa5\x01\n" +
+	"\fRegistration\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x03R\x02id\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\x03R\x06userId\x12\x19\n" +
+	"\bevent_id\x18\x03 \x01(\x03R\aeventId\x129\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\",\n" +
+	"\x0fRegisterRequest\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\"6\n" +
+	"\x19CancelRegistrationRequest\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\"\x1c\n" +
+	"\x1aCancelRegistrationResponse\"\x1c\n" +
+	"\x1aListMyRegistrationsRequest\":\n" +
+	"\x1dListEventRegistrationsRequest\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x03R\aeventId\"a\n" +
+	"\x19ListRegistrationsResponse\x12D\n" +
+	"\rregistrations\x18\x01 \x03(\v2\x1e.eventsbooking.v1.RegistrationR\rregistrations2\xb9\x03\n" +
+	"\rRegistrations\x12M\n" +
+	"\bRegister\x12!.eventsbooking.v1.RegisterRequest\x1a\x1e.eventsbooking.v1.Registration\x12o\n" +
+	"\x12CancelRegistration\x12+.eventsbooking.v1.CancelRegistrationRequest\x1a,.eventsbooking.v1.CancelRegistrationResponse\x12p\n" +
+	"\x13ListMyRegistrations\x12,.eventsbooking.v1.ListMyRegistrationsRequest\x1a+.eventsbooking.v1.ListRegistrationsResponse\x12v\n" +
+	"\x16ListEventRegistrations\x12/.eventsbooking.v1.ListEventRegistrationsRequest\x1a+.eventsbooking.v1.ListRegistrationsResponseB\x16Z\x14events-booking/pb;pbb\x06proto3"
+
+var (
+	file_eventsbooking_v1_registrations_proto_rawDescOnce sync.Once
+	file_eventsbooking_v1_registrations_proto_rawDescData []byte
+)
+
+func file_eventsbooking_v1_registrations_proto_rawDescGZIP() []byte {
+	file_eventsbooking_v1_registrations_proto_rawDescOnce.Do(func() {
+		file_eventsbooking_v1_registrations_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_eventsbooking_v1_registrations_proto_rawDesc), len(file_eventsbooking_v1_registrations_proto_rawDesc)))
+	})
+	return file_eventsbooking_v1_registrations_proto_rawDescData
+}
+
+var file_eventsbooking_v1_registrations_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_eventsbooking_v1_registrations_proto_goTypes = []any{
+	(*Registration)(nil),                  // 0: eventsbooking.v1.Registration
+	(*RegisterRequest)(nil),               // 1: eventsbooking.v1.RegisterRequest
+	(*CancelRegistrationRequest)(nil),     // 2: eventsbooking.v1.CancelRegistrationRequest
+	(*CancelRegistrationResponse)(nil),    // 3: eventsbooking.v1.CancelRegistrationResponse
+	(*ListMyRegistrationsRequest)(nil),    // 4: eventsbooking.v1.ListMyRegistrationsRequest
+	(*ListEventRegistrationsRequest)(nil), // 5: eventsbooking.v1.ListEventRegistrationsRequest
+	(*ListRegistrationsResponse)(nil),     // 6: eventsbooking.v1.ListRegistrationsResponse
+	(*timestamppb.Timestamp)(nil),         // 7: google.protobuf.Timestamp
+}
+var file_eventsbooking_v1_registrations_proto_depIdxs = []int32{
+	7, // 0: eventsbooking.v1.Registration.created_at:type_name -> google.protobuf.Timestamp
+	0, // 1: eventsbooking.v1.ListRegistrationsResponse.registrations:type_name -> eventsbooking.v1.Registration
+	1, // 2: eventsbooking.v1.Registrations.Register:input_type -> eventsbooking.v1.RegisterRequest
+	2, // 3: eventsbooking.v1.Registrations.CancelRegistration:input_type -> eventsbooking.v1.CancelRegistrationRequest
+	4, // 4: eventsbooking.v1.Registrations.ListMyRegistrations:input_type -> eventsbooking.v1.ListMyRegistrationsRequest
+	5, // 5: eventsbooking.v1.Registrations.ListEventRegistrations:input_type -> eventsbooking.v1.ListEventRegistrationsRequest
+	0, // 6: eventsbooking.v1.Registrations.Register:output_type -> eventsbooking.v1.Registration
+	3, // 7: eventsbooking.v1.Registrations.CancelRegistration:output_type -> eventsbooking.v1.CancelRegistrationResponse
+	6, // 8: eventsbooking.v1.Registrations.ListMyRegistrations:output_type -> eventsbooking.v1.ListRegistrationsResponse
+	6, // 9: eventsbooking.v1.Registrations.ListEventRegistrations:output_type -> eventsbooking.v1.ListRegistrationsResponse
+	6, // [6:10] is the sub-list for method output_type
+	2, // [2:6] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_eventsbooking_v1_registrations_proto_init() }
+func file_eventsbooking_v1_registrations_proto_init() {
+	if File_eventsbooking_v1_registrations_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_eventsbooking_v1_registrations_proto_rawDesc), len(file_eventsbooking_v1_registrations_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_eventsbooking_v1_registrations_proto_goTypes,
+		DependencyIndexes: file_eventsbooking_v1_registrations_proto_depIdxs,
+		MessageInfos:      file_eventsbooking_v1_registrations_proto_msgTypes,
+	}.Build()
+	File_eventsbooking_v1_registrations_proto = out.File
+	file_eventsbooking_v1_registrations_proto_goTypes = nil
+	file_eventsbooking_v1_registrations_proto_depIdxs = nil
+}
@@ -0,0 +1,245 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: eventsbooking/v1/registrations.proto
+
+package pb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Registrations_Register_FullMethodName               = "/eventsbooking.v1.Registrations/Register"
+	Registrations_CancelRegistration_FullMethodName     = "/eventsbooking.v1.Registrations/CancelRegistration"
+	Registrations_ListMyRegistrations_FullMethodName    = "/eventsbooking.v1.Registrations/ListMyRegistrations"
+	Registrations_ListEventRegistrations_FullMethodName = "/eventsbooking.v1.Registrations/ListEventRegistrations"
+)
+
+// RegistrationsClient is the client API for Registrations service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Registrations needs a token for every call.
+type RegistrationsClient interface {
+	// Fails with ALREADY_EXISTS when the user is already registered.
+	Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*Registration, error)
+	CancelRegistration(ctx context.Context, in *CancelRegistrationRequest, opts ...grpc.CallOption) (*CancelRegistrationResponse, error)
+	// The registrations of the logged in user.
+	ListMyRegistrations(ctx context.Context, in *ListMyRegistrationsRequest, opts ...grpc.CallOption) (*ListRegistrationsResponse, error)
+	// The registrations of an event, for its hosts only.
+	ListEventRegistrations(ctx context.Context, in *ListEventRegistrationsRequest, opts ...grpc.CallOption) (*ListRegistrationsResponse, error)
+}
+
+type registrationsClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewRegistrationsClient(cc grpc.ClientConnInterface) RegistrationsClient {
+	return &registrationsClient{cc}
+}
+
+func (c *registrationsClient) Register(ctx context.Context, in *RegisterRequest, opts ...grpc.CallOption) (*Registration, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Registration)
+	err := c.cc.Invoke(ctx, Registrations_Register_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registrationsClient) CancelRegistration(ctx context.Context, in *CancelRegistrationRequest, opts ...grpc.CallOption) (*CancelRegistrationResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CancelRegistrationResponse)
+	err := c.cc.Invoke(ctx, Registrations_CancelRegistration_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registrationsClient) ListMyRegistrations(ctx context.Context, in *ListMyRegistrationsRequest, opts ...grpc.CallOption) (*ListRegistrationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRegistrationsResponse)
+	err := c.cc.Invoke(ctx, Registrations_ListMyRegistrations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *registrationsClient) ListEventRegistrations(ctx context.Context, in *ListEventRegistrationsRequest, opts ...grpc.CallOption) (*ListRegistrationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRegistrationsResponse)
+	err := c.cc.Invoke(ctx, Registrations_ListEventRegistrations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// RegistrationsServer is the server API for Registrations service.
+// All implementations must embed UnimplementedRegistrationsServer
+// for forward compatibility.
+//
+// Registrations needs a token for every call.
+type RegistrationsServer interface {
+	// Fails with ALREADY_EXISTS when the user is already registered.
+	Register(context.Context, *RegisterRequest) (*Registration, error)
+	CancelRegistration(context.Context, *CancelRegistrationRequest) (*CancelRegistrationResponse, error)
+	// The registrations of the logged in user.
+	ListMyRegistrations(context.Context, *ListMyRegistrationsRequest) (*ListRegistrationsResponse, error)
+	// The registrations of an event, for its hosts only.
+	ListEventRegistrations(context.Context, *ListEventRegistrationsRequest) (*ListRegistrationsResponse, error)
+	mustEmbedUnimplementedRegistrationsServer()
+}
+
+// UnimplementedRegistrationsServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedRegistrationsServer struct{}
+
+func (UnimplementedRegistrationsServer) Register(context.Context, *RegisterRequest) (*Registration, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Register not implemented")
+}
+func (UnimplementedRegistrationsServer) CancelRegistration(context.Context, *CancelRegistrationRequest) (*CancelRegistrationResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelRegistration not implemented")
+}
+func (UnimplementedRegistrationsServer) ListMyRegistrations(context.Context, *ListMyRegistrationsRequest) (*ListRegistrationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListMyRegistrations not implemented")
+}
+func (UnimplementedRegistrationsServer) ListEventRegistrations(context.Context, *ListEventRegistrationsRequest) (*ListRegistrationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListEventRegistrations not implemented")
+}
+func (UnimplementedRegistrationsServer) mustEmbedUnimplementedRegistrationsServer() {}
+func (UnimplementedRegistrationsServer) testEmbeddedByValue()                       {}
+
+// UnsafeRegistrationsServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to RegistrationsServer will
+// result in compilation errors.
+type UnsafeRegistrationsServer interface {
+	mustEmbedUnimplementedRegistrationsServer()
+}
+
+func RegisterRegistrationsServer(s grpc.ServiceRegistrar, srv RegistrationsServer) {
+	// If the following call pancis, it indicates UnimplementedRegistrationsServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Registrations_ServiceDesc, srv)
+}
+
+func _Registrations_Register_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RegisterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistrationsServer).Register(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registrations_Register_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistrationsServer).Register(ctx, req.(*RegisterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registrations_CancelRegistration_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelRegistrationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistrationsServer).CancelRegistration(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registrations_CancelRegistration_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistrationsServer).CancelRegistration(ctx, req.(*CancelRegistrationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registrations_ListMyRegistrations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListMyRegistrationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistrationsServer).ListMyRegistrations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registrations_ListMyRegistrations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistrationsServer).ListMyRegistrations(ctx, req.(*ListMyRegistrationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Registrations_ListEventRegistrations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListEventRegistrationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RegistrationsServer).ListEventRegistrations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Registrations_ListEventRegistrations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RegistrationsServer).ListEventRegistrations(ctx, req.(*ListEventRegistrationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Registrations_ServiceDesc is the grpc.ServiceDesc for Registrations service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Registrations_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "eventsbooking.v1.Registrations",
+	HandlerType: (*RegistrationsServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Register",
+			Handler:    _Registrations_Register_Handler,
+		},
+		{
+			MethodName: "CancelRegistration",
+			Handler:    _Registrations_CancelRegistration_Handler,
+		},
+		{
+			MethodName: "ListMyRegistrations",
+			Handler:    _Registrations_ListMyRegistrations_Handler,
+		},
+		{
+			MethodName: "ListEventRegistrations",
+			Handler:    _Registrations_ListEventRegistrations_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "eventsbooking/v1/registrations.proto",
+}
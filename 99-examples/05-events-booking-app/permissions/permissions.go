@@ -0,0 +1,49 @@
+// Package permissions decides what a user may do with an event based on their host role.
+package permissions
+
+import (
+	"events-booking/models"
+)
+
+type Action string
+
+const (
+	UpdateEvent          Action = "event:update"
+	DeleteEvent          Action = "event:delete"
+	ManageHosts          Action = "event:hosts"
+	ViewAttendees        Action = "event:attendees"
+	ManageAttendees      Action = "event:attendees:manage"
+	CheckIn              Action = "event:check-in"
+	ManageAttachments    Action = "event:attachments"
+	ApproveRegistrations Action = "event:registrations:approve"
+	ManageInvites        Action = "event:invites"
+	ModerateComments     Action = "event:comments:moderate"
+	ViewFeedback         Action = "event:feedback"
+)
+
+// what every host role is allowed to do, owners can do everything
+var roleActions = map[string][]Action{
+	models.RoleOwner:   {UpdateEvent, DeleteEvent, ManageHosts, ViewAttendees, ManageAttendees, CheckIn, ManageAttachments, ApproveRegistrations, ManageInvites, ModerateComments, ViewFeedback},
+	models.RoleCoHost:  {UpdateEvent, ViewAttendees, ManageAttendees, CheckIn, ManageAttachments, ModerateComments, ViewFeedback},
+	models.RoleChecker: {ViewAttendees, CheckIn},
+}
+
+// Can reports whether the user is allowed to perform the action on the event.
+func Can(userID int64, event *models.Event, action Action) (bool, error) {
+	role, err := models.GetHostRole(event.ID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return RoleAllows(role, action), nil
+}
+
+func RoleAllows(role string, action Action) bool {
+	for _, allowed := range roleActions[role] {
+		if allowed == action {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,125 @@
+// Package reminders reminds registered users of their upcoming events.
+package reminders
+
+import (
+	"context"
+	"events-booking/models"
+	"events-booking/notify"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+)
+
+type Scheduler struct {
+	notifier notify.Notifier
+	offsets  []time.Duration // sorted, smallest first
+	interval time.Duration
+}
+
+// NewScheduler sends a reminder at every offset before an event, e.g. 24h and 1h.
+func NewScheduler(notifier notify.Notifier, offsets []time.Duration, interval time.Duration) *Scheduler {
+	sorted := slices.Clone(offsets)
+	slices.Sort(sorted)
+
+	return &Scheduler{notifier: notifier, offsets: sorted, interval: interval}
+}
+
+// Run checks for due reminders every interval until ctx is cancelled.
+func (s *Scheduler) Run(ctx context.Context) {
+	if len(s.offsets) == 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		err := s.sendDue(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			log.Printf("[reminders] %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) sendDue(ctx context.Context, now time.Time) error {
+	largest := s.offsets[len(s.offsets)-1]
+
+	targets, err := models.GetUpcomingRegistrations(now, now.Add(largest))
+	if err != nil {
+		return fmt.Errorf("could not load upcoming registrations: %w", err)
+	}
+
+	for _, target := range targets {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		err := s.remind(ctx, target, now)
+		if err != nil {
+			log.Printf("[reminders] event %d, user %d: %v", target.EventID, target.UserID, err)
+		}
+	}
+
+	return nil
+}
+
+// remind sends at most one reminder, for the most urgent due offset. Larger offsets
+// that are due at the same time (e.g. an event created 30 minutes before it starts)
+// are claimed too, so the user doesn't get them afterwards.
+func (s *Scheduler) remind(ctx context.Context, target models.ReminderTarget, now time.Time) error {
+	var claimed []time.Duration
+	send := false
+	mostUrgent := true
+
+	// offsets are sorted, so the first due one is the most urgent
+	for _, offset := range s.offsets {
+		if target.EventDate.Add(-offset).After(now) {
+			continue
+		}
+
+		ok, err := models.ClaimReminder(target.EventID, target.UserID, offset)
+		if err != nil {
+			return err
+		}
+
+		if mostUrgent {
+			send = ok
+			mostUrgent = false
+		}
+		if ok {
+			claimed = append(claimed, offset)
+		}
+	}
+
+	if !send {
+		return nil
+	}
+
+	err := s.notifier.Notify(ctx, notify.Notification{
+		Kind:    models.NotifyEventReminder,
+		UserID:  target.UserID,
+		Email:   target.Email,
+		EventID: target.EventID,
+		Subject: fmt.Sprintf("Reminder: %s starts in %s", target.EventName, target.EventDate.Sub(now).Round(time.Minute)),
+		Body:    fmt.Sprintf("%s starts at %s in %s.", target.EventName, target.EventDate.Format(time.RFC1123), target.Location),
+		SentAt:  now.UTC(),
+	})
+	if err != nil {
+		for _, offset := range claimed {
+			releaseErr := models.ReleaseReminder(target.EventID, target.UserID, offset)
+			if releaseErr != nil {
+				log.Printf("[reminders] could not release reminder: %v", releaseErr)
+			}
+		}
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,66 @@
+package routes
+
+import (
+	"errors"
+	"events-booking/models"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func createAPIKey(c *gin.Context) {
+	var key models.APIKey
+
+	err := c.ShouldBindJSON(&key)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if key.ExpiresAt != nil && !key.ExpiresAt.After(time.Now()) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "expires_at must be in the future"})
+		return
+	}
+
+	key.UserID = c.GetInt64("userId")
+	plain, err := key.Save()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the API key.", "error": err.Error()})
+		return
+	}
+
+	// the plain key is not stored, this response is the only chance to copy it
+	c.JSON(http.StatusCreated, gin.H{"message": "API key created. Copy it now, it won't be shown again.", "api_key": key, "key": plain})
+}
+
+func getMyAPIKeys(c *gin.Context) {
+	keys, err := models.GetUserAPIKeys(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the API keys.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"api_keys": keys})
+}
+
+func deleteAPIKey(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the API key ID", "error": err.Error()})
+		return
+	}
+
+	err = models.DeleteAPIKey(c.GetInt64("userId"), id)
+	if errors.Is(err, models.ErrAPIKeyNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "API key not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not revoke the API key.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,197 @@
+package routes
+
+import (
+	"errors"
+	"events-booking/models"
+	"events-booking/permissions"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxAttachmentSize is the largest file accepted in bytes, changed by SetAttachmentMaxSize.
+var maxAttachmentSize int64 = 10 << 20
+
+func SetAttachmentMaxSize(size int64) {
+	maxAttachmentSize = size
+}
+
+// the files never change, only get deleted, so clients and proxies may keep them a day
+const attachmentCacheControl = "public, max-age=86400"
+
+// uploadAttachment takes one file in the multipart field "file".
+func uploadAttachment(c *gin.Context) {
+	// room for the multipart headers around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxAttachmentSize+64<<10)
+
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageAttachments, "Only event hosts can upload attachments.") {
+		return
+	}
+
+	file, header, err := c.Request.FormFile("file")
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) || err == nil && header.Size > maxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("The file must be at most %d bytes.", maxAttachmentSize)})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Send the file as multipart/form-data in the field \"file\".", "error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxAttachmentSize+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not read the file.", "error": err.Error()})
+		return
+	}
+	if int64(len(data)) > maxAttachmentSize {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"message": fmt.Sprintf("The file must be at most %d bytes.", maxAttachmentSize)})
+		return
+	}
+
+	attachment, err := models.SaveAttachment(c.Request.Context(), event.ID, c.GetInt64("userId"), header.Filename, data)
+	if errors.Is(err, models.ErrUnsupportedFileType) {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Only images (JPEG, PNG, GIF) and PDFs can be attached.", "error": err.Error()})
+		return
+	}
+	if errors.Is(err, models.ErrTooManyAttachments) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Delete an attachment first, the event has the most it can have.", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save the attachment.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Attachment uploaded", "attachment": attachment})
+}
+
+func getEventAttachments(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	attachments, err := models.GetEventAttachments(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the attachments.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attachments": attachments})
+}
+
+func downloadAttachment(c *gin.Context) {
+	serveAttachment(c, false)
+}
+
+func downloadAttachmentThumbnail(c *gin.Context) {
+	serveAttachment(c, true)
+}
+
+// serveAttachment answers range requests, If-None-Match and If-Modified-Since
+// through http.ServeContent.
+func serveAttachment(c *gin.Context, thumbnail bool) {
+	event, attachment, ok := loadAttachment(c)
+	if !ok {
+		return
+	}
+
+	file, err := attachment.Open(c.Request.Context(), thumbnail)
+	if errors.Is(err, models.ErrAttachmentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not read the attachment.", "error": err.Error()})
+		return
+	}
+	defer file.Close()
+
+	contentType, etag, disposition := attachment.ContentType, attachment.SHA256[:32], "attachment"
+	if thumbnail {
+		contentType, etag = "image/jpeg", etag+"-thumb"
+	}
+	// only images are shown in the browser, anything else is downloaded
+	if strings.HasPrefix(contentType, "image/") {
+		disposition = "inline"
+	}
+
+	c.Header("Content-Type", contentType)
+	c.Header("ETag", `"`+etag+`"`)
+	if event.Visibility == models.VisibilityPrivate {
+		// shared caches must not hand the files of private events to others
+		c.Header("Cache-Control", "private, max-age=86400")
+	} else {
+		c.Header("Cache-Control", attachmentCacheControl)
+	}
+	c.Header("X-Content-Type-Options", "nosniff")
+	if header := mime.FormatMediaType(disposition, map[string]string{"filename": attachment.Filename}); header != "" {
+		c.Header("Content-Disposition", header)
+	} else {
+		c.Header("Content-Disposition", disposition)
+	}
+
+	http.ServeContent(c.Writer, c.Request, "", attachment.CreatedAt, file)
+}
+
+func deleteAttachment(c *gin.Context) {
+	event, attachment, ok := loadAttachment(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageAttachments, "Only event hosts can delete attachments.") {
+		return
+	}
+
+	err := attachment.Delete()
+	if errors.Is(err, models.ErrAttachmentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the attachment.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Attachment deleted"})
+}
+
+// loadAttachment reads the event and attachment from the :id and :attachmentId
+// path params and responds with 400/404/500 when it can't.
+func loadAttachment(c *gin.Context) (*models.Event, *models.Attachment, bool) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return nil, nil, false
+	}
+
+	id, err := strconv.ParseInt(c.Param("attachmentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the attachment ID", "error": err.Error()})
+		return nil, nil, false
+	}
+
+	attachment, err := models.GetAttachment(event.ID, id)
+	if errors.Is(err, models.ErrAttachmentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Attachment not found"})
+		return nil, nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the attachment.", "error": err.Error()})
+		return nil, nil, false
+	}
+
+	return event, attachment, true
+}
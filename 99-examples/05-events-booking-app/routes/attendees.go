@@ -0,0 +1,193 @@
+package routes
+
+import (
+	"encoding/csv"
+	"errors"
+	events "events-booking/models"
+	"events-booking/permissions"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var registrationStatuses = []string{
+	events.RegistrationPending, events.RegistrationConfirmed, events.RegistrationCancelled,
+	events.RegistrationAttended, events.RegistrationNoShow,
+}
+
+// getEventAttendees answers JSON, or CSV for ?format=csv / Accept: text/csv.
+// ?status= only lists the registrations in that status.
+func getEventAttendees(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ViewAttendees, "Only event hosts can see the attendees.") {
+		return
+	}
+
+	status := c.Query("status")
+	if status != "" && !slices.Contains(registrationStatuses, status) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "status must be one of " + strings.Join(registrationStatuses, ", ") + "."})
+		return
+	}
+
+	attendees, err := events.GetEventAttendees(event.ID, status)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the attendees.", "error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "csv" || strings.Contains(c.GetHeader("Accept"), "text/csv") {
+		writeAttendeesCSV(c, event, attendees)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"attendees": attendees})
+}
+
+func writeAttendeesCSV(c *gin.Context, event *events.Event, attendees []events.Attendee) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d-attendees.csv"`, event.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"user_id", "email", "registered_at", "status"})
+
+	for _, a := range attendees {
+		registeredAt := ""
+		if a.RegisteredAt != nil {
+			registeredAt = a.RegisteredAt.Format(time.RFC3339)
+		}
+		w.Write([]string{strconv.FormatInt(a.UserID, 10), csvCell(a.Email), registeredAt, a.Status})
+	}
+
+	flushCSV(c, w)
+}
+
+func removeEventAttendee(c *gin.Context) {
+	changeAttendee(c, permissions.ManageAttendees, "Only the event owner and co-hosts can remove attendees.",
+		"Attendee removed successfully", func(event *events.Event, attendeeId int64, actorId int64) error {
+			return event.CancelRegistration(attendeeId, actorId, "")
+		})
+}
+
+func markAttendeeNoShow(c *gin.Context) {
+	changeAttendee(c, permissions.CheckIn, "Only event hosts can mark no-shows.",
+		"Attendee marked as no-show", func(event *events.Event, attendeeId int64, actorId int64) error {
+			return event.MarkNoShow(attendeeId, actorId)
+		})
+}
+
+func checkInAttendee(c *gin.Context) {
+	changeAttendee(c, permissions.CheckIn, "Only event hosts can check attendees in.",
+		"Attendee checked in", func(event *events.Event, attendeeId int64, actorId int64) error {
+			return event.MarkAttended(attendeeId, actorId)
+		})
+}
+
+func approveAttendee(c *gin.Context) {
+	changeAttendee(c, permissions.ApproveRegistrations, "Only the event owner can approve registrations.",
+		"Registration approved", func(event *events.Event, attendeeId int64, actorId int64) error {
+			return event.ApproveRegistration(attendeeId, actorId)
+		})
+}
+
+// rejectAttendee takes an optional {"reason": "..."} that is kept in the history.
+func rejectAttendee(c *gin.Context) {
+	var body struct {
+		Reason string `json:"reason" binding:"max=500"`
+	}
+	if c.Request.ContentLength != 0 {
+		err := c.ShouldBindJSON(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	changeAttendee(c, permissions.ApproveRegistrations, "Only the event owner can reject registrations.",
+		"Registration rejected", func(event *events.Event, attendeeId int64, actorId int64) error {
+			return event.RejectRegistration(attendeeId, actorId, body.Reason)
+		})
+}
+
+// getAttendeeHistory lists the status changes of an attendee's registration.
+func getAttendeeHistory(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ViewAttendees, "Only event hosts can see the attendees.") {
+		return
+	}
+
+	attendeeId, ok := attendeeIDParam(c)
+	if !ok {
+		return
+	}
+
+	history, err := events.GetRegistrationHistory(event.ID, attendeeId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the history.", "error": err.Error()})
+		return
+	}
+	if len(history) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"message": "The user is not registered for this event."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"history": history})
+}
+
+// changeAttendee runs a status change of the :userId registration for a host
+// allowed to do action.
+func changeAttendee(c *gin.Context, action permissions.Action, forbidden string, success string,
+	change func(event *events.Event, attendeeId int64, actorId int64) error) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, action, forbidden) {
+		return
+	}
+
+	attendeeId, ok := attendeeIDParam(c)
+	if !ok {
+		return
+	}
+
+	err := change(event, attendeeId, c.GetInt64("userId"))
+	if errors.Is(err, events.ErrRegistrationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "The user is not registered for this event."})
+		return
+	}
+	if errors.Is(err, events.ErrInvalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not change the registration.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": success})
+}
+
+func attendeeIDParam(c *gin.Context) (int64, bool) {
+	attendeeId, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the user ID", "error": err.Error()})
+		return 0, false
+	}
+
+	return attendeeId, true
+}
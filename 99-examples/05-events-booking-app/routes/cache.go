@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"events-booking/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+func getCacheStats(c *gin.Context) {
+	stats, enabled := models.EventCacheStats()
+	if !enabled {
+		c.JSON(http.StatusOK, gin.H{"message": "The event cache is turned off (CACHE_SIZE=0)."})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cache": stats})
+}
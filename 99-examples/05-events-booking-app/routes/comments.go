@@ -0,0 +1,284 @@
+package routes
+
+import (
+	"errors"
+	events "events-booking/models"
+	"events-booking/permissions"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type commentBody struct {
+	Body     string `json:"body" binding:"required,max=5000"`
+	ParentID *int64 `json:"parent_id"`
+}
+
+// getComments pages through the threads of the event, newest first. The first
+// page also has the pinned threads. ?cursor= takes next_cursor of the previous page.
+func getComments(c *gin.Context) {
+	listComments(c, false)
+}
+
+// getCommentReplies pages through the replies of a thread, oldest first.
+func getCommentReplies(c *gin.Context) {
+	listComments(c, true)
+}
+
+func listComments(c *gin.Context, replies bool) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	after, limit, ok := cursorPage(c)
+	if !ok {
+		return
+	}
+
+	withHidden, ok := canModerateComments(c, event)
+	if !ok {
+		return
+	}
+
+	var parentID *int64
+	if replies {
+		id, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the comment ID", "error": err.Error()})
+			return
+		}
+
+		thread, err := events.GetThread(event.ID, id)
+		if errors.Is(err, events.ErrCommentNotFound) || err == nil && thread.Hidden && !withHidden {
+			c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found"})
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the comment.", "error": err.Error()})
+			return
+		}
+		parentID = &thread.ID
+	}
+
+	comments, next, err := events.GetComments(event.ID, parentID, after, limit, withHidden)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the comments.", "error": err.Error()})
+		return
+	}
+
+	response := gin.H{"comments": comments, "next_cursor": nil}
+	if next != 0 {
+		response["next_cursor"] = encodeCursor(next)
+	}
+
+	if !replies && after == 0 {
+		pinned, err := events.GetPinnedComments(event.ID, withHidden)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the comments.", "error": err.Error()})
+			return
+		}
+		response["pinned"] = pinned
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// createComment takes {"body": markdown, "parent_id": optional thread to reply to}.
+func createComment(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	var body commentBody
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId := c.GetInt64("userId")
+	allowed, err := events.CanComment(event.ID, userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check the permissions.", "error": err.Error()})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only hosts and registered attendees can comment on the event."})
+		return
+	}
+
+	comment, err := events.CreateComment(event.ID, userId, body.ParentID, body.Body)
+	if errors.Is(err, events.ErrCommentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "The comment to reply to was not found."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save the comment.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Comment posted", "comment": comment})
+}
+
+// updateComment takes {"body": markdown}, only the author can edit.
+func updateComment(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := loadOwnComment(c, event, "Only the author can edit the comment.")
+	if !ok {
+		return
+	}
+
+	var body commentBody
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = comment.Edit(body.Body)
+	if !respondCommentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment updated", "comment": comment})
+}
+
+func deleteComment(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	comment, ok := loadOwnComment(c, event, "Only the author can delete the comment.")
+	if !ok {
+		return
+	}
+
+	err := comment.Delete()
+	if !respondCommentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Comment deleted"})
+}
+
+func hideComment(c *gin.Context) {
+	moderateComment(c, "Comment hidden", func(comment *events.Comment, userId int64) error {
+		return comment.SetHidden(true, userId)
+	})
+}
+
+func unhideComment(c *gin.Context) {
+	moderateComment(c, "Comment shown again", func(comment *events.Comment, userId int64) error {
+		return comment.SetHidden(false, userId)
+	})
+}
+
+func pinComment(c *gin.Context) {
+	moderateComment(c, "Comment pinned", func(comment *events.Comment, userId int64) error {
+		return comment.SetPinned(true)
+	})
+}
+
+func unpinComment(c *gin.Context) {
+	moderateComment(c, "Comment unpinned", func(comment *events.Comment, userId int64) error {
+		return comment.SetPinned(false)
+	})
+}
+
+func moderateComment(c *gin.Context, success string, change func(comment *events.Comment, userId int64) error) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ModerateComments, "Only the event owner and co-hosts can moderate comments.") {
+		return
+	}
+
+	comment, ok := loadComment(c, event)
+	if !ok {
+		return
+	}
+
+	err := change(comment, c.GetInt64("userId"))
+	if !respondCommentError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": success, "comment": comment})
+}
+
+// respondCommentError answers the errors of the comment writes and reports whether there was none.
+func respondCommentError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, events.ErrCommentNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found"})
+	case errors.Is(err, events.ErrCannotPinReply):
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not change the comment.", "error": err.Error()})
+	}
+	return false
+}
+
+// loadComment reads the comment from the :commentId path param and responds with 400/404/500 when it can't.
+func loadComment(c *gin.Context, event *events.Event) (*events.Comment, bool) {
+	id, err := strconv.ParseInt(c.Param("commentId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the comment ID", "error": err.Error()})
+		return nil, false
+	}
+
+	comment, err := events.GetComment(event.ID, id)
+	if errors.Is(err, events.ErrCommentNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Comment not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the comment.", "error": err.Error()})
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// loadOwnComment is loadComment for the author only, everyone else gets 403.
+func loadOwnComment(c *gin.Context, event *events.Event, forbidden string) (*events.Comment, bool) {
+	comment, ok := loadComment(c, event)
+	if !ok {
+		return nil, false
+	}
+
+	if comment.UserID == nil || *comment.UserID != c.GetInt64("userId") {
+		c.JSON(http.StatusForbidden, gin.H{"message": forbidden})
+		return nil, false
+	}
+
+	return comment, true
+}
+
+// canModerateComments tells whether the caller sees hidden comments, anonymous callers never do.
+func canModerateComments(c *gin.Context, event *events.Event) (bool, bool) {
+	userId := c.GetInt64("userId")
+	if userId == 0 {
+		return false, true
+	}
+
+	allowed, err := permissions.Can(userId, event, permissions.ModerateComments)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check the permissions.", "error": err.Error()})
+		return false, false
+	}
+
+	return allowed, true
+}
@@ -0,0 +1,95 @@
+package routes
+
+import (
+	events "events-booking/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// eventETag identifies one version of an event, it changes on every update.
+func eventETag(e *events.Event) string {
+	return fmt.Sprintf(`"%d-%d"`, e.ID, e.Version)
+}
+
+// ratedEventETag is the ETag of GET /events/:id, which also changes with the
+// rating shown next to the event. Ratings don't touch the version, so If-Match
+// only compares the eventETag part of it.
+func ratedEventETag(e *events.Event, rating events.RatingSummary) string {
+	average := "none"
+	if rating.Average != nil {
+		average = strconv.FormatFloat(*rating.Average, 'f', -1, 64)
+	}
+	return fmt.Sprintf(`"%d-%d-%d-%s"`, e.ID, e.Version, rating.Count, average)
+}
+
+// withoutRating turns a ratedEventETag back into the eventETag it starts with.
+func withoutRating(etag string) string {
+	parts := strings.SplitN(strings.Trim(etag, `"`), "-", 3)
+	if len(parts) < 3 {
+		return etag
+	}
+	return `"` + parts[0] + "-" + parts[1] + `"`
+}
+
+// etagListMatches reports whether an If-Match / If-None-Match header lists etag.
+// If-Match needs the strong comparison, so weak (W/) tags only count when weak is true.
+// normalize, when set, is applied to every listed tag first.
+func etagListMatches(header string, etag string, weak bool, normalize func(string) string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" {
+			return true
+		}
+
+		if strings.HasPrefix(candidate, "W/") {
+			if !weak {
+				continue
+			}
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if normalize != nil {
+			candidate = normalize(candidate)
+		}
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkIfMatch makes writes conditional on the version the client last read.
+// It responds with 428 when the header is missing and 412 when it is stale,
+// and returns false in both cases so the handler can stop.
+func checkIfMatch(c *gin.Context, e *events.Event) bool {
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		c.JSON(http.StatusPreconditionRequired, gin.H{"message": "If-Match header is required. Fetch the event first and send its ETag."})
+		return false
+	}
+
+	if !etagListMatches(ifMatch, eventETag(e), false, withoutRating) {
+		c.Header("ETag", eventETag(e))
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": "The event was modified by someone else. Fetch it again and retry."})
+		return false
+	}
+
+	return true
+}
+
+// notModified answers 304 when the client already has the current version.
+func notModified(c *gin.Context, etag string) bool {
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	if ifNoneMatch == "" || !etagListMatches(ifNoneMatch, etag, true, nil) {
+		return false
+	}
+
+	c.Header("ETag", etag)
+	c.Status(http.StatusNotModified)
+	return true
+}
@@ -0,0 +1,29 @@
+package routes
+
+import (
+	"encoding/csv"
+	"log"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// csvCell keeps spreadsheets from running text that users typed as a formula,
+// see https://owasp.org/www-community/attacks/CSV_Injection
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// flushCSV ends a CSV export. The status is already sent by then, so a failed
+// write can only be logged and the response cut short.
+func flushCSV(c *gin.Context, w *csv.Writer) {
+	w.Flush()
+	err := w.Error()
+	if err != nil {
+		log.Printf("[csv] could not write %s: %v", c.Request.URL.Path, err)
+		c.Abort()
+	}
+}
@@ -1,15 +1,73 @@
 package routes
 
 import (
+	"database/sql"
+	"encoding/json"
+	"errors"
 	events "events-booking/models"
+	"events-booking/permissions"
+	"events-booking/utils"
+	"io"
+	"mime"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 )
 
+// eventFilterFromQuery reads ?category=<slug>, ?tag=<name> (repeatable) and the
+// date range ?from=&to=, and responds with 400 when the dates can't be parsed.
+func eventFilterFromQuery(c *gin.Context) (events.EventFilter, bool) {
+	filter := events.EventFilter{
+		OrgID:    activeOrgID(c),
+		Category: c.Query("category"),
+		Tags:     c.QueryArray("tag"),
+	}
+
+	var err error
+	filter.From, err = parseDateParam(c.Query("from"), false)
+	if err == nil {
+		filter.To, err = parseDateParam(c.Query("to"), true)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Use RFC 3339 (2027-10-10T18:00:00Z) or YYYY-MM-DD for from and to.", "error": err.Error()})
+		return filter, false
+	}
+
+	return filter, true
+}
+
+// parseDateParam takes RFC 3339 or a plain date in UTC. A plain date as the end
+// of a range includes that whole day.
+func parseDateParam(value string, end bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return &t, nil
+	}
+
+	t, err = time.Parse(time.DateOnly, value)
+	if err != nil {
+		return nil, err
+	}
+	if end {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
 func getAllEvents(c *gin.Context) {
-	e, err := events.GetAllEvents()
+	filter, ok := eventFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	e, err := events.GetEvents(filter)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve events. Please try again later.", "error": err.Error()})
 		return
@@ -22,22 +80,42 @@ func getAllEvents(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"events": e})
 }
 
-func getEventByID(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+// getEventFacets returns event counts per category and tag for the same filters as getAllEvents.
+func getEventFacets(c *gin.Context) {
+	filter, ok := eventFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	facets, err := events.GetEventFacets(filter)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the id", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the facets.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"facets": facets})
+}
+
+func getEventByID(c *gin.Context) {
+	e, ok := loadEvent(c)
+	if !ok {
+		return
 	}
-	e, err := events.GetEventByID(id)
+
+	// the average rating comes with the event and is part of the ETag
+	rating, err := events.GetRatingSummary(e.ID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event. Please try again later.", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
 		return
 	}
 
-	if e.ID == 0 {
-		c.JSON(http.StatusNotFound, gin.H{"message": "Event not found"})
+	etag := ratedEventETag(e, rating)
+	if notModified(c, etag) {
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"event": e})
+
+	c.Header("ETag", etag)
+	c.JSON(http.StatusOK, gin.H{"event": e, "rating": rating})
 }
 
 func createEvent(c *gin.Context) {
@@ -49,11 +127,22 @@ func createEvent(c *gin.Context) {
 		return
 	}
 
+	// only members can create events in an organization
+	if activeOrg(c) != nil && c.GetString("orgRole") == "" {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only members of the organization can create events in it."})
+		return
+	}
+
 	// This value was set from the middleware - Auth
 	userId := c.GetInt64("userId")
 	e.UserID = userId
+	e.OrgID = activeOrgID(c)
 
 	err = e.Save()
+	if errors.Is(err, events.ErrUnknownCategory) || errors.Is(err, events.ErrInvalidCoordinates) || errors.Is(err, events.ErrInvalidEndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the event. Please try again later.", "error": err.Error()})
 		return
@@ -62,64 +151,148 @@ func createEvent(c *gin.Context) {
 }
 
 func updateEvent(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the event ID", "error": err.Error()})
+	event, ok := loadEvent(c)
+	if !ok {
 		return
 	}
 
-	event, err := events.GetEventByID(id)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
+	if !authorize(c, event, permissions.UpdateEvent, "Only event hosts can update the info.") {
 		return
 	}
 
-	loggedInUserID := c.GetInt64("userId")
-	if loggedInUserID != event.UserID {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Only event owners can update the info."})
+	if !checkIfMatch(c, event) {
 		return
 	}
 
 	var updatedEvent events.Event
 
-	err = c.ShouldBindJSON(&updatedEvent)
+	err := c.ShouldBindJSON(&updatedEvent)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	updatedEvent.ID = id
+	updatedEvent.ID = event.ID
+	updatedEvent.UserID = event.UserID
+	updatedEvent.Version = event.Version
+	updatedEvent.OrgID = event.OrgID
 	err = updatedEvent.Update()
+	if errors.Is(err, events.ErrUnknownCategory) || errors.Is(err, events.ErrInvalidCoordinates) || errors.Is(err, events.ErrInvalidEndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, events.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": "The event was modified by someone else. Fetch it again and retry."})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update the event.", "error": err.Error()})
 		return
 	}
 
+	c.Header("ETag", eventETag(&updatedEvent))
 	c.JSON(http.StatusCreated, gin.H{"message": "Event updated successfully", "event": updatedEvent})
 }
 
-func deleteEvent(c *gin.Context) {
-	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+// patchEvent applies a JSON Merge Patch, so only the changed fields need to be sent.
+func patchEvent(c *gin.Context) {
+	mediaType, _, _ := mime.ParseMediaType(c.ContentType())
+	if mediaType != "application/merge-patch+json" && mediaType != "application/json" {
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"message": "Use Content-Type application/merge-patch+json."})
+		return
+	}
 
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.UpdateEvent, "Only event hosts can update the info.") {
+		return
+	}
+
+	if !checkIfMatch(c, event) {
+		return
+	}
+
+	patch, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the event ID", "error": err.Error()})
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not read the request body.", "error": err.Error()})
 		return
 	}
 
-	e, err := events.GetEventByID(id)
+	current, err := json.Marshal(event)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update the event.", "error": err.Error()})
+		return
+	}
+
+	merged, err := utils.MergePatch(current, patch)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not apply the patch.", "error": err.Error()})
+		return
+	}
+
+	var patchedEvent events.Event
+	err = json.Unmarshal(merged, &patchedEvent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not apply the patch.", "error": err.Error()})
+		return
+	}
+
+	// the patch can't move the event to another id, owner, version or organization
+	patchedEvent.ID = event.ID
+	patchedEvent.UserID = event.UserID
+	patchedEvent.Version = event.Version
+	patchedEvent.OrgID = event.OrgID
+	if patchedEvent.Tags == nil {
+		// "tags": null removes all tags, Update would otherwise keep them
+		patchedEvent.Tags = []string{}
+	}
+
+	err = binding.Validator.ValidateStruct(&patchedEvent)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = patchedEvent.Update()
+	if errors.Is(err, events.ErrUnknownCategory) || errors.Is(err, events.ErrInvalidCoordinates) || errors.Is(err, events.ErrInvalidEndTime) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, events.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": "The event was modified by someone else. Fetch it again and retry."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update the event.", "error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", eventETag(&patchedEvent))
+	c.JSON(http.StatusOK, gin.H{"message": "Event updated successfully", "event": patchedEvent})
+}
+
+func deleteEvent(c *gin.Context) {
+	e, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, e, permissions.DeleteEvent, "Only event owners can delete the info.") {
 		return
 	}
 
-	loggedInUserID := c.GetInt64("userId")
-	if loggedInUserID != e.UserID {
-		c.JSON(http.StatusForbidden, gin.H{"message": "Only event owners can delete the info."})
+	if !checkIfMatch(c, e) {
 		return
 	}
 
-	err = e.Delete()
+	err := e.Delete()
+	if errors.Is(err, events.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": "The event was modified by someone else. Fetch it again and retry."})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the event.", "error": err.Error()})
 		return
@@ -127,3 +300,45 @@ func deleteEvent(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Event deleted successfully", "event": e})
 }
+
+// loadEvent reads the event from the :id path param and responds with 400/404/500 when it can't.
+// Private events the caller may not see answer 404 like missing ones, so their existence isn't revealed.
+func loadEvent(c *gin.Context) (*events.Event, bool) {
+	event, ok := findEvent(c)
+	if !ok {
+		return nil, false
+	}
+
+	allowed, err := events.CanViewEvent(c.GetInt64("userId"), event)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
+		return nil, false
+	}
+	if !allowed {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Event not found"})
+		return nil, false
+	}
+
+	return event, true
+}
+
+// findEvent is loadEvent without the visibility check, for letting users into private events.
+func findEvent(c *gin.Context) (*events.Event, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the event ID", "error": err.Error()})
+		return nil, false
+	}
+
+	event, err := events.GetOrgEventByID(activeOrgID(c), id)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Event not found"})
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
+		return nil, false
+	}
+
+	return event, true
+}
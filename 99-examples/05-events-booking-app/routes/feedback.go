@@ -0,0 +1,134 @@
+package routes
+
+import (
+	"encoding/csv"
+	"errors"
+	events "events-booking/models"
+	"events-booking/permissions"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// submitFeedback takes {"rating": 1-5, "review": optional text}, sending it again replaces it.
+func submitFeedback(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Rating int    `json:"rating" binding:"required,min=1,max=5"`
+		Review string `json:"review" binding:"max=2000"`
+	}
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	feedback, err := event.SubmitFeedback(c.GetInt64("userId"), body.Rating, body.Review)
+	if errors.Is(err, events.ErrFeedbackNotOpen) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Feedback opens once the event is over.", "opens_at": event.EndTime()})
+		return
+	}
+	if errors.Is(err, events.ErrCannotGiveFeedback) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only attendees of the event can give feedback."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not save the feedback.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Thanks for the feedback!", "feedback": feedback})
+}
+
+func getMyFeedback(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	feedback, err := events.GetFeedback(event.ID, c.GetInt64("userId"))
+	if errors.Is(err, events.ErrFeedbackNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "You haven't given feedback for this event."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not fetch the feedback.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"feedback": feedback})
+}
+
+// getEventFeedback answers the feedback with the stats as JSON, or CSV for ?format=csv / Accept: text/csv.
+func getEventFeedback(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ViewFeedback, "Only the event owner and co-hosts can see the feedback.") {
+		return
+	}
+
+	feedback, err := events.GetEventFeedback(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the feedback.", "error": err.Error()})
+		return
+	}
+
+	if c.Query("format") == "csv" || strings.Contains(c.GetHeader("Accept"), "text/csv") {
+		writeFeedbackCSV(c, event, feedback)
+		return
+	}
+
+	stats, err := events.GetFeedbackStats(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the feedback.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats, "feedback": feedback})
+}
+
+func getFeedbackStats(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ViewFeedback, "Only the event owner and co-hosts can see the feedback.") {
+		return
+	}
+
+	stats, err := events.GetFeedbackStats(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the feedback.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"stats": stats})
+}
+
+func writeFeedbackCSV(c *gin.Context, event *events.Event, feedback []events.Feedback) {
+	c.Header("Content-Type", "text/csv; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="event-%d-feedback.csv"`, event.ID))
+	c.Status(http.StatusOK)
+
+	w := csv.NewWriter(c.Writer)
+	w.Write([]string{"user_id", "email", "rating", "review", "submitted_at", "updated_at"})
+
+	for _, f := range feedback {
+		w.Write([]string{strconv.FormatInt(f.UserID, 10), csvCell(f.Email), strconv.Itoa(f.Rating), csvCell(f.Review),
+			f.CreatedAt.Format(time.RFC3339), f.UpdatedAt.Format(time.RFC3339)})
+	}
+
+	flushCSV(c, w)
+}
@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"events-booking/db"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// A rating changes what GET /events/:id shows, but is no edit of the event:
+// cached copies must be refreshed while the hosts' If-Match keeps working.
+func TestFeedbackChangesTheETagButNotTheVersion(t *testing.T) {
+	hostToken := signupAndLogin(t, "feedback-host@example.com")
+	attendeeToken := signupAndLogin(t, "feedback-attendee@example.com")
+	eventID := createTestEvent(t, hostToken)
+	path := fmt.Sprintf("/events/%d", eventID)
+
+	w, _ := request(t, http.MethodPost, path+"/register", attendeeToken, nil, nil)
+	if w.Code >= 300 {
+		t.Fatalf("registering answered %d: %s", w.Code, w.Body)
+	}
+	// feedback opens once the event is over
+	_, err := db.DB.Exec(`UPDATE events SET date = ? WHERE id = ?`, time.Now().Add(-48*time.Hour), eventID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w, read := request(t, http.MethodGet, path, "", nil, nil)
+	etag := w.Header().Get("ETag")
+	version := read["event"].(map[string]any)["version"]
+
+	w, _ = request(t, http.MethodPost, path+"/feedback", attendeeToken, gin.H{"rating": 4}, nil)
+	if w.Code >= 300 {
+		t.Fatalf("the feedback answered %d: %s", w.Code, w.Body)
+	}
+
+	w, read = request(t, http.MethodGet, path, "", nil, http.Header{"If-None-Match": {etag}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET with the ETag from before the rating answered %d, want 200", w.Code)
+	}
+	if read["event"].(map[string]any)["version"] != version {
+		t.Errorf("the rating changed the version from %v to %v", version, read["event"].(map[string]any)["version"])
+	}
+	if read["rating"].(map[string]any)["count"] != float64(1) {
+		t.Errorf("rating %v, want one", read["rating"])
+	}
+
+	w, _ = request(t, http.MethodGet, path, "", nil, http.Header{"If-None-Match": {w.Header().Get("ETag")}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("GET with the current ETag answered %d, want 304", w.Code)
+	}
+
+	header := http.Header{"If-Match": {etag}, "Content-Type": {"application/merge-patch+json"}}
+	w, _ = request(t, http.MethodPatch, path, hostToken, gin.H{"location": "Room 2"}, header)
+	if w.Code != http.StatusOK {
+		t.Errorf("PATCH with the ETag from before the rating answered %d: %s", w.Code, w.Body)
+	}
+}
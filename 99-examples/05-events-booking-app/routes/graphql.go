@@ -0,0 +1,76 @@
+package routes
+
+import (
+	"events-booking/graph"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type graphqlRequest struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// postGraphQL runs a GraphQL query or mutation for the (optionally) logged in user
+// inside the active organization. Field errors still answer 200, like GraphQL servers do.
+func postGraphQL(c *gin.Context) {
+	var request graphqlRequest
+	err := c.ShouldBindJSON(&request)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	viewer := graph.Viewer{
+		UserID:  c.GetInt64("userId"),
+		OrgID:   activeOrgID(c),
+		OrgRole: c.GetString("orgRole"),
+	}
+
+	result, executed := graph.Execute(c.Request.Context(), viewer, graph.Params{
+		Query:         request.Query,
+		OperationName: request.OperationName,
+		Variables:     request.Variables,
+	})
+	if !executed {
+		c.JSON(http.StatusBadRequest, result)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// getGraphiQL serves the GraphiQL IDE, it posts to the URL it was loaded from.
+// Put the token in the headers tab: {"Authorization": "<token>"}
+func getGraphiQL(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(graphiQLPage))
+}
+
+const graphiQLPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+	<meta charset="utf-8">
+	<title>Events Booking GraphiQL</title>
+	<style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+	<link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+	<script crossorigin src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+	<script crossorigin src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+	<script crossorigin src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+</head>
+<body>
+	<div id="graphiql">Loading...</div>
+	<script>
+		const fetcher = GraphiQL.createFetcher({ url: window.location.pathname });
+		ReactDOM.createRoot(document.getElementById('graphiql')).render(
+			React.createElement(GraphiQL, {
+				fetcher: fetcher,
+				defaultHeaders: '{"Authorization": ""}',
+				defaultQuery: '{\n  events {\n    id\n    name\n    date\n    organizer { id }\n  }\n}\n',
+			}),
+		);
+	</script>
+</body>
+</html>
+`
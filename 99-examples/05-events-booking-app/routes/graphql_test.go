@@ -0,0 +1,51 @@
+package routes
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGraphQLEmailNeedsLogin(t *testing.T) {
+	token := signupAndLogin(t, "graphql-email@example.com")
+	w, me := request(t, http.MethodGet, "/me", token, nil, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("GET /me answered %d: %s", w.Code, w.Body)
+	}
+	query := gin.H{"query": fmt.Sprintf(`{ user(id: %v) { id email } }`, me["id"])}
+
+	tests := []struct {
+		name      string
+		token     string
+		wantEmail any
+		wantCode  any
+	}{
+		{"anonymous", "", nil, "UNAUTHENTICATED"},
+		{"logged in", token, "graphql-email@example.com", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w, answer := request(t, http.MethodPost, "/graphql", tt.token, query, nil)
+			if w.Code != http.StatusOK {
+				t.Fatalf("answered %d: %s", w.Code, w.Body)
+			}
+
+			user, _ := answer["data"].(map[string]any)["user"].(map[string]any)
+			if user == nil || user["email"] != tt.wantEmail {
+				t.Errorf("user is %v, want the email %v", user, tt.wantEmail)
+			}
+
+			var code any
+			errs, _ := answer["errors"].([]any)
+			if len(errs) > 0 {
+				code = errs[0].(map[string]any)["extensions"].(map[string]any)["code"]
+			}
+			if code != tt.wantCode {
+				t.Errorf("error code is %v, want %v: %s", code, tt.wantCode, w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	events "events-booking/models"
+	"events-booking/permissions"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type hostInvite struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type ownershipTransfer struct {
+	UserID int64 `json:"user_id" binding:"required"`
+}
+
+func getEventHosts(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	role, err := events.GetHostRole(event.ID, c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check the permissions.", "error": err.Error()})
+		return
+	}
+	if role == "" {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only event hosts can see the hosts."})
+		return
+	}
+
+	hosts, err := events.GetEventHosts(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the hosts.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"hosts": hosts})
+}
+
+func inviteEventHost(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageHosts, "Only event owners can invite hosts.") {
+		return
+	}
+
+	var invite hostInvite
+	err := c.ShouldBindJSON(&invite)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId, err := events.GetUserIDByEmail(invite.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No user with this email."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the user.", "error": err.Error()})
+		return
+	}
+
+	err = event.InviteHost(userId, invite.Role, c.GetInt64("userId"))
+	if errors.Is(err, events.ErrInvalidHostRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, events.ErrAlreadyHost) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not invite the host.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Host invited successfully", "user_id": userId, "role": invite.Role})
+}
+
+func acceptHostInvite(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	err := event.AcceptHostInvite(c.GetInt64("userId"))
+	if errors.Is(err, events.ErrHostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No pending host invite for this event."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not accept the invite.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Host invite accepted"})
+}
+
+// removeEventHost lets the owner remove anyone and a host remove themselves.
+func removeEventHost(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	hostId, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the user ID", "error": err.Error()})
+		return
+	}
+
+	if hostId != c.GetInt64("userId") && !authorize(c, event, permissions.ManageHosts, "Only event owners can remove hosts.") {
+		return
+	}
+
+	err = event.RemoveHost(hostId)
+	if errors.Is(err, events.ErrOwnerNotRemoved) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, events.ErrHostNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not remove the host.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Host removed successfully"})
+}
+
+func transferEventOwnership(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageHosts, "Only event owners can transfer the ownership.") {
+		return
+	}
+
+	var transfer ownershipTransfer
+	err := c.ShouldBindJSON(&transfer)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = event.TransferOwnership(transfer.UserID)
+	if errors.Is(err, events.ErrAlreadyOwner) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "You already own the event, pick another host."})
+		return
+	}
+	if errors.Is(err, events.ErrHostNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The new owner has to be an accepted host of the event."})
+		return
+	}
+	if errors.Is(err, events.ErrVersionConflict) {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"message": "The event was modified by someone else. Fetch it again and retry."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not transfer the ownership.", "error": err.Error()})
+		return
+	}
+
+	c.Header("ETag", eventETag(event))
+	c.JSON(http.StatusOK, gin.H{"message": "Ownership transferred successfully", "event": event})
+}
@@ -0,0 +1,139 @@
+package routes
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"events-booking/db"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Responses carrying a secret must not end up in idempotency_keys, where they
+// would sit in plain text for a day and be replayed to anyone with the key.
+func TestIdempotencyStoresNoSecrets(t *testing.T) {
+	token := signupAndLogin(t, "secrets@example.com")
+	eventID := createTestEvent(t, token)
+
+	// the cases run in order, the TOTP ones build on each other
+	var totpSecret string
+	var recoveryCodes []string
+
+	tests := []struct {
+		name    string
+		path    string
+		body    func() any
+		secrets func(answer map[string]any) []string
+	}{
+		{"API key", "/me/api-keys",
+			func() any { return gin.H{"name": "ci", "scopes": []string{"events:read"}} },
+			func(answer map[string]any) []string {
+				return []string{answer["key"].(string)}
+			}},
+		{"TOTP setup", "/me/mfa/totp",
+			func() any { return nil },
+			func(answer map[string]any) []string {
+				totpSecret = answer["secret"].(string)
+				return []string{totpSecret, answer["otpauth_uri"].(string), answer["qr_code"].(string)}
+			}},
+		{"TOTP confirmation", "/me/mfa/totp/confirm",
+			func() any { return gin.H{"code": totpCode(t, totpSecret, time.Now())} },
+			func(answer map[string]any) []string {
+				recoveryCodes = stringList(answer["recovery_codes"])
+				return recoveryCodes
+			}},
+		{"new recovery codes", "/me/mfa/recovery-codes",
+			func() any { return gin.H{"code": recoveryCodes[0]} },
+			func(answer map[string]any) []string {
+				return stringList(answer["recovery_codes"])
+			}},
+		{"invite token", fmt.Sprintf("/events/%d/invites", eventID),
+			func() any { return gin.H{"max_uses": 5} },
+			func(answer map[string]any) []string {
+				return []string{answer["token"].(string)}
+			}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{"Idempotency-Key": {"key-" + tt.name}}
+			w, answer := request(t, http.MethodPost, tt.path, token, tt.body(), header)
+			if w.Code >= http.StatusBadRequest {
+				t.Fatalf("answered %d: %s", w.Code, w.Body)
+			}
+
+			secrets := tt.secrets(answer)
+			if len(secrets) == 0 {
+				t.Fatal("the answer has no secret")
+			}
+			for _, secret := range secrets {
+				if secret == "" {
+					t.Fatal("the answer has an empty secret")
+				}
+
+				var stored int
+				err := db.DB.QueryRow(`SELECT COUNT(*) FROM idempotency_keys WHERE instr(body, ?) > 0`, secret).Scan(&stored)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if stored > 0 {
+					t.Errorf("%q is stored in idempotency_keys", secret)
+				}
+			}
+		})
+	}
+}
+
+func TestIdempotencyLimitsTheBody(t *testing.T) {
+	token := signupAndLogin(t, "large-body@example.com")
+
+	event := gin.H{"name": "Meetup", "description": strings.Repeat("a", 1<<20), "date": time.Now().Add(24 * time.Hour), "location": "Room 1"}
+	w, _ := request(t, http.MethodPost, "/events", token, event, http.Header{"Idempotency-Key": {"large-body"}})
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("a body over the limit answered %d: %s", w.Code, w.Body)
+	}
+
+	var stored int
+	err := db.DB.QueryRow(`SELECT COUNT(*) FROM idempotency_keys WHERE key = ?`, "large-body").Scan(&stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stored != 0 {
+		t.Errorf("the refused request claimed its key")
+	}
+}
+
+// stringList converts a decoded JSON array of strings.
+func stringList(value any) []string {
+	var list []string
+	for _, v := range value.([]any) {
+		list = append(list, v.(string))
+	}
+	return list
+}
+
+// totpCode is the code an authenticator app shows at the time (RFC 6238, SHA1, 6 digits).
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1_000_000)
+}
@@ -0,0 +1,211 @@
+package routes
+
+import (
+	"errors"
+	events "events-booking/models"
+	"events-booking/permissions"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultInviteLifetime = 7 * 24 * time.Hour
+	maxInviteLifetime     = 90 * 24 * time.Hour
+)
+
+// createInvite takes {"max_uses": 1-1000, "expires_at": RFC 3339}, both optional:
+// one use and a week by default. The token is only in this response.
+func createInvite(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageInvites, "Only the event owner can manage invites.") {
+		return
+	}
+
+	var body struct {
+		MaxUses   int        `json:"max_uses" binding:"omitempty,min=1,max=1000"`
+		ExpiresAt *time.Time `json:"expires_at"`
+	}
+	if c.Request.ContentLength != 0 {
+		err := c.ShouldBindJSON(&body)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	now := time.Now()
+	if body.MaxUses == 0 {
+		body.MaxUses = 1
+	}
+	expiresAt := now.Add(defaultInviteLifetime)
+	if body.ExpiresAt != nil {
+		expiresAt = *body.ExpiresAt
+	}
+	if !expiresAt.After(now) || expiresAt.After(now.Add(maxInviteLifetime)) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "expires_at must be in the future and at most 90 days away."})
+		return
+	}
+
+	invite, token, err := events.CreateEventInvite(event.ID, c.GetInt64("userId"), body.MaxUses, expiresAt)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the invite.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Invite created, share the token now, it is not shown again.", "invite": invite, "token": token})
+}
+
+func getInvites(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageInvites, "Only the event owner can manage invites.") {
+		return
+	}
+
+	invites, err := events.GetEventInvites(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the invites.", "error": err.Error()})
+		return
+	}
+
+	codeSet, err := events.HasEventAccessCode(event.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the invites.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"invites": invites, "access_code_set": codeSet})
+}
+
+func revokeInvite(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageInvites, "Only the event owner can manage invites.") {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("inviteId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the invite ID", "error": err.Error()})
+		return
+	}
+
+	err = events.RevokeEventInvite(event.ID, id)
+	if errors.Is(err, events.ErrInviteNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Invite not found or already revoked"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not revoke the invite.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Invite revoked"})
+}
+
+// setAccessCode takes {"code": "..."}, anyone knowing it can get into the event.
+func setAccessCode(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageInvites, "Only the event owner can manage invites.") {
+		return
+	}
+
+	var body struct {
+		Code string `json:"code" binding:"required,min=8,max=64"`
+	}
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = events.SetEventAccessCode(event.ID, body.Code)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not set the access code.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access code set"})
+}
+
+func deleteAccessCode(c *gin.Context) {
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
+
+	if !authorize(c, event, permissions.ManageInvites, "Only the event owner can manage invites.") {
+		return
+	}
+
+	err := events.SetEventAccessCode(event.ID, "")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not remove the access code.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Access code removed"})
+}
+
+// redeemAccess takes {"token": "..."} from an invite or {"code": "..."} and lets
+// the caller into a private event. A wrong token or code answers 404, like an
+// event the caller may not see.
+func redeemAccess(c *gin.Context) {
+	event, ok := findEvent(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Token string `json:"token" binding:"required_without=Code"`
+		Code  string `json:"code" binding:"required_without=Token,max=64"`
+	}
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	if event.Visibility != events.VisibilityPrivate {
+		c.JSON(http.StatusOK, gin.H{"message": "The event is open to everyone.", "event": event})
+		return
+	}
+
+	userId := c.GetInt64("userId")
+	if body.Token != "" {
+		err = events.RedeemEventInvite(event.ID, userId, body.Token)
+	} else {
+		err = events.RedeemAccessCode(event.ID, userId, body.Code)
+	}
+	if errors.Is(err, events.ErrInviteNotFound) || errors.Is(err, events.ErrWrongAccessCode) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Event not found"})
+		return
+	}
+	if errors.Is(err, events.ErrInviteUnavailable) {
+		c.JSON(http.StatusGone, gin.H{"message": "The invite expired, was revoked or was used up. Ask the organizer for a new one."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not use the invite.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "You can now see and register for the event.", "event": event})
+}
@@ -0,0 +1,73 @@
+package routes
+
+import (
+	"errors"
+	"events-booking/jobs"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getJobs lists jobs by ?status= (failed by default) so admins can inspect what went wrong.
+func getJobs(c *gin.Context) {
+	status := c.DefaultQuery("status", jobs.StatusFailed)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 500"})
+		return
+	}
+
+	list, err := jobs.List(status, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the jobs.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"jobs": list})
+}
+
+func getJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the job ID", "error": err.Error()})
+		return
+	}
+
+	job, err := jobs.Get(id)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the job.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"job": job})
+}
+
+func retryJob(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the job ID", "error": err.Error()})
+		return
+	}
+
+	err = jobs.Retry(id)
+	if errors.Is(err, jobs.ErrJobNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No failed job with this ID."})
+		return
+	}
+	if errors.Is(err, jobs.ErrDuplicate) {
+		c.JSON(http.StatusConflict, gin.H{"message": "A job with the same unique key is already queued or running."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retry the job.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Job queued again"})
+}
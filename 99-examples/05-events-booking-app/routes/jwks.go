@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"events-booking/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getJWKS publishes the public keys so other services can verify our tokens.
+// Retiring keys stay listed until they are removed from the keyset.
+func getJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, gin.H{"keys": utils.JWKS()})
+}
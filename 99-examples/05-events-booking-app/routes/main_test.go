@@ -0,0 +1,121 @@
+package routes
+
+import (
+	"bytes"
+	"encoding/json"
+	"events-booking/db"
+	"events-booking/utils"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TestMain runs the tests against a fresh events.db in a temporary directory.
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "routes-test")
+	if err != nil {
+		panic(err)
+	}
+	err = os.Chdir(dir)
+	if err != nil {
+		panic(err)
+	}
+	db.InitDB()
+
+	keys, err := utils.GenerateKeyset()
+	if err != nil {
+		panic(err)
+	}
+	utils.ConfigureJWT(utils.JWTConfig{Keys: keys, Issuer: "events-booking", Audience: "events-booking"})
+	// the cheapest hash, the tests sign up a lot of users
+	err = utils.ConfigurePasswordHashing(utils.PasswordHashing{
+		Algorithm:         utils.HashBcrypt,
+		BcryptCost:        bcrypt.MinCost,
+		Argon2Memory:      8,
+		Argon2Iterations:  1,
+		Argon2Parallelism: 1,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	testServer = gin.New()
+	RegisterRoutes(testServer)
+
+	code := m.Run()
+	db.DB.Close()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+var testServer *gin.Engine
+
+const testPassword = "correct horse battery"
+
+// request sends body as JSON, with the token unless it is empty, and decodes the JSON answer.
+func request(t *testing.T, method string, path string, token string, body any, header http.Header) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	var payload []byte
+	if body != nil {
+		var err error
+		payload, err = json.Marshal(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	req := httptest.NewRequest(method, path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+
+	w := httptest.NewRecorder()
+	testServer.ServeHTTP(w, req)
+
+	var answer map[string]any
+	if w.Body.Len() > 0 {
+		// CSV and other downloads leave the map empty
+		json.Unmarshal(w.Body.Bytes(), &answer)
+	}
+	return w, answer
+}
+
+// createTestEvent creates an event owned by the user of the token and returns its ID.
+func createTestEvent(t *testing.T, token string) int64 {
+	t.Helper()
+
+	event := gin.H{"name": "Meetup", "description": "A meetup", "date": time.Now().Add(24 * time.Hour), "location": "Room 1"}
+	w, answer := request(t, http.MethodPost, "/events", token, event, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("creating the event answered %d: %s", w.Code, w.Body)
+	}
+	return int64(answer["event"].(map[string]any)["id"].(float64))
+}
+
+// signupAndLogin creates the user and returns a token for it.
+func signupAndLogin(t *testing.T, email string) string {
+	t.Helper()
+
+	credentials := gin.H{"email": email, "password": testPassword}
+	w, _ := request(t, http.MethodPost, "/signup", "", credentials, nil)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("signup answered %d: %s", w.Code, w.Body)
+	}
+
+	w, answer := request(t, http.MethodPost, "/login", "", credentials, nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login answered %d: %s", w.Code, w.Body)
+	}
+	return answer["token"].(string)
+}
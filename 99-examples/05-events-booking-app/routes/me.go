@@ -0,0 +1,145 @@
+package routes
+
+import (
+	"errors"
+	"events-booking/models"
+	"events-booking/utils"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func getMe(c *gin.Context) {
+	profile, err := models.GetProfile(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the profile.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// profileUpdate only changes the fields that are sent.
+type profileUpdate struct {
+	DisplayName       *string `json:"display_name" binding:"omitempty,max=100"`
+	Timezone          *string `json:"timezone"`
+	NotificationPrefs *struct {
+		RegistrationConfirmations *bool `json:"registration_confirmations"`
+		EventReminders            *bool `json:"event_reminders"`
+		EventUpdates              *bool `json:"event_updates"`
+		EventReschedules          *bool `json:"event_reschedules"`
+		EventCancellations        *bool `json:"event_cancellations"`
+	} `json:"notification_prefs"`
+}
+
+func updateMe(c *gin.Context) {
+	var update profileUpdate
+
+	err := c.ShouldBindJSON(&update)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	profile, err := models.GetProfile(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the profile.", "error": err.Error()})
+		return
+	}
+
+	if update.DisplayName != nil {
+		profile.DisplayName = *update.DisplayName
+	}
+	if update.Timezone != nil {
+		profile.Timezone = *update.Timezone
+	}
+	if prefs := update.NotificationPrefs; prefs != nil {
+		if prefs.RegistrationConfirmations != nil {
+			profile.NotificationPrefs.RegistrationConfirmations = *prefs.RegistrationConfirmations
+		}
+		if prefs.EventReminders != nil {
+			profile.NotificationPrefs.EventReminders = *prefs.EventReminders
+		}
+		if prefs.EventUpdates != nil {
+			profile.NotificationPrefs.EventUpdates = *prefs.EventUpdates
+		}
+		if prefs.EventReschedules != nil {
+			profile.NotificationPrefs.EventReschedules = *prefs.EventReschedules
+		}
+		if prefs.EventCancellations != nil {
+			profile.NotificationPrefs.EventCancellations = *prefs.EventCancellations
+		}
+	}
+
+	err = profile.Update()
+	if errors.Is(err, models.ErrInvalidTimezone) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Use an IANA timezone like Europe/Berlin.", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update the profile.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+type passwordChange struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+// changePassword logs out every other session, the caller's token keeps working.
+func changePassword(c *gin.Context) {
+	var change passwordChange
+
+	err := c.ShouldBindJSON(&change)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = models.ChangePassword(c.GetInt64("userId"), c.GetInt64("sessionId"), change.CurrentPassword, change.NewPassword)
+	if errors.Is(err, models.ErrWrongPassword) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "The current password is wrong."})
+		return
+	}
+	if errors.Is(err, utils.ErrWeakPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Please choose a stronger password.", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not change the password.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Password changed, other sessions are logged out."})
+}
+
+func exportMe(c *gin.Context) {
+	export, err := models.ExportUserData(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not export the account.", "error": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("events-booking-export-%s.json", export.ExportedAt.Format(time.DateOnly))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, filename))
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+func deleteMe(c *gin.Context) {
+	err := models.DeleteAccount(c.GetInt64("userId"))
+	if errors.Is(err, models.ErrLastOrgAdmin) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Make someone else an admin of your organizations first.", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the account.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Account deleted"})
+}
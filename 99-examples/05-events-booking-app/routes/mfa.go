@@ -0,0 +1,162 @@
+package routes
+
+import (
+	"encoding/base64"
+	"errors"
+	"events-booking/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/skip2/go-qrcode"
+)
+
+type mfaCode struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type mfaLogin struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+func getMFAStatus(c *gin.Context) {
+	status, err := models.GetMFAStatus(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the two-factor status.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, status)
+}
+
+// enrollTOTP returns the secret as text and as a QR code (a PNG data URI) for the authenticator app.
+func enrollTOTP(c *gin.Context) {
+	enrollment, err := models.EnrollTOTP(c.GetInt64("userId"))
+	if errors.Is(err, models.ErrMFAAlreadyEnabled) {
+		c.JSON(http.StatusConflict, gin.H{"message": "Two-factor authentication is already on, turn it off first to use another app."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not set up two-factor authentication.", "error": err.Error()})
+		return
+	}
+
+	png, err := qrcode.Encode(enrollment.URI, qrcode.Medium, 256)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the QR code.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":     "Scan the QR code with your authenticator app, then confirm with a code from it.",
+		"secret":      enrollment.Secret,
+		"otpauth_uri": enrollment.URI,
+		"qr_code":     "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	})
+}
+
+func confirmTOTP(c *gin.Context) {
+	var body mfaCode
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := models.ConfirmTOTP(c.GetInt64("userId"), body.Code)
+	if !respondMFAError(c, err) {
+		return
+	}
+
+	// like API keys, the recovery codes are only stored hashed
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication is on. Keep the recovery codes somewhere safe, they won't be shown again.", "recovery_codes": codes})
+}
+
+func regenerateRecoveryCodes(c *gin.Context) {
+	var body mfaCode
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	codes, err := models.RegenerateRecoveryCodes(c.GetInt64("userId"), body.Code)
+	if !respondMFAError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "New recovery codes, the old ones stopped working.", "recovery_codes": codes})
+}
+
+func disableTOTP(c *gin.Context) {
+	var body mfaCode
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = models.DisableTOTP(c.GetInt64("userId"), body.Code)
+	if !respondMFAError(c, err) {
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Two-factor authentication is off."})
+}
+
+// finishMFALogin is the second step of /login for users with two-factor authentication.
+func finishMFALogin(c *gin.Context) {
+	var body mfaLogin
+	err := c.ShouldBindJSON(&body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId, err := models.FinishMFAChallenge(body.MFAToken, body.Code)
+	if errors.Is(err, models.ErrMFAChallengeNotFound) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "The login has expired. Please log in again."})
+		return
+	}
+	if errors.Is(err, models.ErrTooManyMFAAttempts) {
+		c.JSON(http.StatusTooManyRequests, gin.H{"message": "Too many wrong codes. Please wait a few minutes and log in again."})
+		return
+	}
+	if errors.Is(err, models.ErrInvalidMFACode) {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Invalid Credentials", "error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not finish the login.", "error": err.Error()})
+		return
+	}
+
+	user, err := models.GetUserByID(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not finish the login.", "error": err.Error()})
+		return
+	}
+
+	token, err := models.IssueToken(userId, sessionClient(c))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create a token.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "User Logged in Successfully", "user": user.Email, "token": token})
+}
+
+// respondMFAError answers the errors the MFA settings share and reports whether there was none.
+func respondMFAError(c *gin.Context, err error) bool {
+	switch {
+	case err == nil:
+		return true
+	case errors.Is(err, models.ErrMFANotEnabled):
+		c.JSON(http.StatusConflict, gin.H{"message": "Two-factor authentication is not set up.", "error": err.Error()})
+	case errors.Is(err, models.ErrInvalidMFACode):
+		c.JSON(http.StatusForbidden, gin.H{"message": "The code is wrong or was already used.", "error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not change two-factor authentication.", "error": err.Error()})
+	}
+	return false
+}
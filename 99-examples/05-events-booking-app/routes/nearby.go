@@ -0,0 +1,59 @@
+package routes
+
+import (
+	"errors"
+	events "events-booking/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultNearbyRadiusKm = 25
+	maxNearbyRadiusKm     = 500
+)
+
+// getNearbyEvents lists the events around ?lat=&lon= within ?radius_km=, closest
+// first. The filters of getAllEvents apply as well.
+func getNearbyEvents(c *gin.Context) {
+	filter, ok := eventFilterFromQuery(c)
+	if !ok {
+		return
+	}
+
+	latitude, err := strconv.ParseFloat(c.Query("lat"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "lat must be a number between -90 and 90.", "error": err.Error()})
+		return
+	}
+	longitude, err := strconv.ParseFloat(c.Query("lon"), 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "lon must be a number between -180 and 180.", "error": err.Error()})
+		return
+	}
+
+	radius := float64(defaultNearbyRadiusKm)
+	if value := c.Query("radius_km"); value != "" {
+		radius, err = strconv.ParseFloat(value, 64)
+		if err != nil || !(radius > 0 && radius <= maxNearbyRadiusKm) {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "radius_km must be above 0 and at most 500."})
+			return
+		}
+	}
+
+	nearby, err := events.GetNearbyEvents(filter, latitude, longitude, radius)
+	if errors.Is(err, events.ErrInvalidCoordinates) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve events. Please try again later.", "error": err.Error()})
+		return
+	}
+
+	if nearby == nil {
+		nearby = []events.NearbyEvent{}
+	}
+	c.JSON(http.StatusOK, gin.H{"events": nearby, "radius_km": radius})
+}
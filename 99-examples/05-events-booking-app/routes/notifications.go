@@ -0,0 +1,80 @@
+package routes
+
+import (
+	"errors"
+	"events-booking/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// getMyNotifications pages through the inbox, newest first. ?unread=true only
+// lists the unread ones, ?cursor= takes next_cursor of the previous page.
+func getMyNotifications(c *gin.Context) {
+	before, limit, ok := cursorPage(c)
+	if !ok {
+		return
+	}
+
+	userId := c.GetInt64("userId")
+	notifications, next, err := models.GetNotifications(userId, c.Query("unread") == "true", before, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the notifications.", "error": err.Error()})
+		return
+	}
+
+	unread, err := models.CountUnreadNotifications(userId)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the notifications.", "error": err.Error()})
+		return
+	}
+
+	response := gin.H{"notifications": notifications, "unread_count": unread, "next_cursor": nil}
+	if next != 0 {
+		response["next_cursor"] = encodeCursor(next)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// getUnreadNotificationCount is cheap enough to poll for a badge.
+func getUnreadNotificationCount(c *gin.Context) {
+	unread, err := models.CountUnreadNotifications(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not count the notifications.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+}
+
+func markNotificationRead(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the notification ID", "error": err.Error()})
+		return
+	}
+
+	err = models.MarkNotificationRead(c.GetInt64("userId"), id)
+	if errors.Is(err, models.ErrNotificationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Notification not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not mark the notification as read.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Notification marked as read"})
+}
+
+func markAllNotificationsRead(c *gin.Context) {
+	marked, err := models.MarkAllNotificationsRead(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not mark the notifications as read.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "All notifications marked as read", "marked": marked})
+}
@@ -0,0 +1,174 @@
+package routes
+
+import (
+	"database/sql"
+	"errors"
+	events "events-booking/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+type orgMemberInvite struct {
+	Email string `json:"email" binding:"required,email"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type orgSettings struct {
+	Name       string `json:"name" binding:"omitempty,max=100"`
+	Visibility string `json:"visibility" binding:"omitempty,oneof=public members"`
+}
+
+// requireOrgAdmin stops the request unless the user is an admin of the active organization.
+func requireOrgAdmin(c *gin.Context) bool {
+	if c.GetString("orgRole") != events.OrgRoleAdmin {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only organization admins can do this."})
+		return false
+	}
+	return true
+}
+
+func createOrganization(c *gin.Context) {
+	var org events.Organization
+
+	err := c.ShouldBindJSON(&org)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err = org.Save(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create the organization. The slug must be unique.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Organization created successfully", "organization": org})
+}
+
+func getMyOrganizations(c *gin.Context) {
+	orgs, err := events.GetUserOrganizations(c.GetInt64("userId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the organizations.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"organizations": orgs})
+}
+
+func getOrganization(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"organization": activeOrg(c), "role": c.GetString("orgRole")})
+}
+
+func updateOrganization(c *gin.Context) {
+	if !requireOrgAdmin(c) {
+		return
+	}
+
+	var settings orgSettings
+	err := c.ShouldBindJSON(&settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	org := *activeOrg(c)
+	if settings.Name != "" {
+		org.Name = settings.Name
+	}
+	if settings.Visibility != "" {
+		org.Visibility = settings.Visibility
+	}
+
+	err = org.Update()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not update the organization.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Organization updated successfully", "organization": org})
+}
+
+func getOrgMembers(c *gin.Context) {
+	if c.GetString("orgRole") == "" {
+		c.JSON(http.StatusForbidden, gin.H{"message": "Only members can see the members."})
+		return
+	}
+
+	members, err := events.GetOrgMembers(activeOrg(c).ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the members.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+func addOrgMember(c *gin.Context) {
+	if !requireOrgAdmin(c) {
+		return
+	}
+
+	var invite orgMemberInvite
+	err := c.ShouldBindJSON(&invite)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	userId, err := events.GetUserIDByEmail(invite.Email)
+	if errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "No user with this email."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the user.", "error": err.Error()})
+		return
+	}
+
+	err = activeOrg(c).AddMember(userId, invite.Role)
+	if errors.Is(err, events.ErrInvalidOrgRole) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, events.ErrAlreadyOrgMember) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not add the member.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusCreated, gin.H{"message": "Member added successfully", "user_id": userId, "role": invite.Role})
+}
+
+// removeOrgMember lets admins remove anyone and members leave on their own.
+func removeOrgMember(c *gin.Context) {
+	memberId, err := strconv.ParseInt(c.Param("userId"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the user ID", "error": err.Error()})
+		return
+	}
+
+	if memberId != c.GetInt64("userId") && !requireOrgAdmin(c) {
+		return
+	}
+
+	err = activeOrg(c).RemoveMember(memberId)
+	if errors.Is(err, events.ErrOrgMemberNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": err.Error()})
+		return
+	}
+	if errors.Is(err, events.ErrLastOrgAdmin) {
+		c.JSON(http.StatusConflict, gin.H{"message": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not remove the member.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
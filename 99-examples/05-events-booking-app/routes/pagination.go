@@ -0,0 +1,55 @@
+package routes
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// page sizes of the cursor paged listings
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// cursorPage reads ?cursor= and ?limit=, responding with 400 when they are invalid.
+func cursorPage(c *gin.Context) (int64, int, bool) {
+	limit := defaultPageSize
+	if value := c.Query("limit"); value != "" {
+		var err error
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > maxPageSize {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "limit must be between 1 and 100"})
+			return 0, 0, false
+		}
+	}
+
+	var after int64
+	if cursor := c.Query("cursor"); cursor != "" {
+		var ok bool
+		after, ok = decodeCursor(cursor)
+		if !ok {
+			c.JSON(http.StatusBadRequest, gin.H{"message": "Invalid cursor, use next_cursor of the previous page."})
+			return 0, 0, false
+		}
+	}
+
+	return after, limit, true
+}
+
+// cursors are opaque to clients, so the paging can change without breaking them
+func encodeCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+func decodeCursor(cursor string) (int64, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, false
+	}
+
+	id, err := strconv.ParseInt(string(raw), 10, 64)
+	return id, err == nil && id > 0
+}
@@ -0,0 +1,26 @@
+package routes
+
+import (
+	events "events-booking/models"
+	"events-booking/permissions"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// authorize checks the logged in user against the event's hosts and responds
+// with 403 (or 500) when the action is not allowed.
+func authorize(c *gin.Context, event *events.Event, action permissions.Action, message string) bool {
+	allowed, err := permissions.Can(c.GetInt64("userId"), event, action)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not check the permissions.", "error": err.Error()})
+		return false
+	}
+
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"message": message})
+		return false
+	}
+
+	return true
+}
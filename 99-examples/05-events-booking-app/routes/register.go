@@ -1,53 +1,84 @@
 package routes
 
 import (
+	"errors"
 	events "events-booking/models"
 	"net/http"
-	"strconv"
 
 	"github.com/gin-gonic/gin"
 )
 
 func registerToEvent(c *gin.Context) {
 	userId := c.GetInt64("userId")
-	eventid, err := strconv.ParseInt(c.Param("id"), 10, 64)
-
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the event ID", "error": err.Error()})
+	event, ok := loadEvent(c)
+	if !ok {
 		return
 	}
 
-	event, err := events.GetEventByID(eventid)
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
+	registration, err := event.Register(userId)
+	if errors.Is(err, events.ErrAlreadyRegistered) {
+		c.JSON(http.StatusConflict, gin.H{"message": "You are already registered for this event."})
+		return
+	}
+	if errors.Is(err, events.ErrRegistrationRemoved) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "The organizer removed you from this event, you can't sign up again."})
 		return
 	}
-
-	err = event.Register(userId)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not register for the event.", "error": err.Error()})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "Successfully registered for the event."})
+	if registration.Status == events.RegistrationPending {
+		c.JSON(http.StatusAccepted, gin.H{"message": "Registration received, the organizer has to approve it.", "registration": registration})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Successfully registered for the event.", "registration": registration})
 }
 
-func deleteRegisteration(c *gin.Context) {
+// getMyRegistration shows the caller's registration with its status history.
+func getMyRegistration(c *gin.Context) {
 	userId := c.GetInt64("userId")
-	eventid, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	event, ok := loadEvent(c)
+	if !ok {
+		return
+	}
 
+	registration, err := events.GetRegistration(event.ID, userId)
+	if errors.Is(err, events.ErrRegistrationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "You are not registered for this event."})
+		return
+	}
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the event ID", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the registration.", "error": err.Error()})
 		return
 	}
 
-	event, err := events.GetEventByID(eventid)
+	history, err := events.GetRegistrationHistory(event.ID, userId)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the event.", "error": err.Error()})
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the registration.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"registration": registration, "history": history})
+}
+
+func deleteRegisteration(c *gin.Context) {
+	userId := c.GetInt64("userId")
+	event, ok := loadEvent(c)
+	if !ok {
 		return
 	}
 
-	err = event.DeleteRegistration(userId)
+	err := event.CancelRegistration(userId, userId, "")
+	if errors.Is(err, events.ErrRegistrationNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "You are not registered for this event."})
+		return
+	}
+	if errors.Is(err, events.ErrInvalidTransition) {
+		c.JSON(http.StatusConflict, gin.H{"message": "The registration can't be cancelled anymore.", "error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not delete the registration for the event.", "error": err.Error()})
 		return
@@ -2,32 +2,186 @@ package routes
 
 import (
 	"events-booking/middlewares"
+	"events-booking/models"
 
 	"github.com/gin-gonic/gin"
 )
 
 func RegisterRoutes(server *gin.Engine) {
-	server.GET("/events", getAllEvents)     // Endpoint to get all events
-	server.GET("/events/:id", getEventByID) // Endpoint to get a specific event by ID
+	// the event routes work outside any organization (or in the one named by the X-Org header)
+	// and again below /orgs/:org for the organization in the path
+	registerEventRoutes(server.Group("/"))
+	registerEventRoutes(server.Group("/orgs/:org"))
+
+	authOnly := server.Group("/")
+	authOnly.Use(middlewares.Authenticate, middlewares.Idempotency)
+
+	authOnly.GET("/users", getAllUsers) // Endpoint to list the users
+
+	authOnly.POST("/orgs", createOrganization) // Endpoint to create an organization
+	authOnly.GET("/orgs", getMyOrganizations)  // Endpoint to list the organizations of the user
+
+	// API keys are managed with a login only, never with another API key
+	authOnly.GET("/me/api-keys", getMyAPIKeys)        // Endpoint to list the user's API keys
+	authOnly.DELETE("/me/api-keys/:id", deleteAPIKey) // Endpoint to revoke an API key
+
+	// the account itself, also a login only
+	authOnly.GET("/me", getMe)                    // Endpoint to get the user's profile
+	authOnly.PATCH("/me", updateMe)               // Endpoint to change the profile, only the fields sent
+	authOnly.POST("/me/password", changePassword) // Endpoint to change the password, logs out other sessions
+	authOnly.GET("/me/export", exportMe)          // Endpoint to download everything stored about the user
+	authOnly.DELETE("/me", deleteMe)              // Endpoint to delete and anonymize the account
+
+	authOnly.GET("/me/notifications", getMyNotifications)                      // Endpoint to read the inbox, ?unread=true&cursor=&limit=
+	authOnly.GET("/me/notifications/unread-count", getUnreadNotificationCount) // Endpoint to count the unread notifications
+	authOnly.POST("/me/notifications/read-all", markAllNotificationsRead)      // Endpoint to mark the whole inbox as read
+	authOnly.POST("/me/notifications/:id/read", markNotificationRead)          // Endpoint to mark one notification as read
+
+	authOnly.GET("/me/sessions", getMySessions)          // Endpoint to list where the user is logged in
+	authOnly.DELETE("/me/sessions/:id", deleteMySession) // Endpoint to log out one session
+	authOnly.DELETE("/me/sessions", deleteAllMySessions) // Endpoint to log out everywhere, ?keep_current=true keeps this one
+
+	authOnly.GET("/me/mfa", getMFAStatus)        // Endpoint to check whether two-factor authentication is on
+	authOnly.DELETE("/me/mfa/totp", disableTOTP) // Endpoint to turn TOTP off with a code
+
+	// responses carrying a secret skip Idempotency, it would store them in plain text
+	secretApis := server.Group("/")
+	secretApis.Use(middlewares.Authenticate)
+
+	secretApis.POST("/me/api-keys", createAPIKey)                      // Endpoint to create an API key, returned once
+	secretApis.POST("/me/mfa/totp", enrollTOTP)                        // Endpoint to start the TOTP setup, returns the secret and QR code
+	secretApis.POST("/me/mfa/totp/confirm", confirmTOTP)               // Endpoint to turn TOTP on with a first code, returns recovery codes
+	secretApis.POST("/me/mfa/recovery-codes", regenerateRecoveryCodes) // Endpoint to replace the recovery codes
+
+	orgApis := server.Group("/orgs/:org")
+	orgApis.Use(middlewares.Authenticate, middlewares.Tenant)
+
+	orgApis.GET("", getOrganization)                    // Endpoint to get the organization and the user's role
+	orgApis.PATCH("", updateOrganization)               // Endpoint to rename or change the visibility (org admins)
+	orgApis.GET("/members", getOrgMembers)              // Endpoint to list the members
+	orgApis.POST("/members", addOrgMember)              // Endpoint to add a member (org admins)
+	orgApis.DELETE("/members/:userId", removeOrgMember) // Endpoint to remove a member or leave
+
+	// only admins can manage the categories and the job queue
+	adminApis := server.Group("/")
+	adminApis.Use(middlewares.Authenticate, middlewares.RequireAdmin)
+
+	adminApis.POST("/categories", createCategory)
+	adminApis.PUT("/categories/:id", updateCategory)
+	adminApis.DELETE("/categories/:id", deleteCategory)
+
+	adminApis.GET("/registrations", getAllRegistrations) // every registration of every user
+
+	adminApis.GET("/admin/jobs", getJobs) // ?status=failed by default
+	adminApis.GET("/admin/jobs/:id", getJob)
+	adminApis.POST("/admin/jobs/:id/retry", retryJob) // queue a failed job again
+
+	adminApis.GET("/admin/cache", getCacheStats) // hits and misses of the event cache
+
+	server.GET("/categories", getAllCategories) // Endpoint to list the event categories
+	server.POST("/signup", userSignup)          // Endpoint to sign up for users
+	server.POST("/login", userLogin)            // Endpoint  to login the user
+	server.POST("/login/mfa", finishMFALogin)   // Endpoint to finish the login with a TOTP or recovery code
+
+	server.GET("/auth/oidc/login", startSSOLogin)     // Endpoint to sign in with the company's OIDC provider
+	server.GET("/auth/oidc/callback", finishSSOLogin) // the provider redirects back here, answers with our JWT
+
+	server.GET("/.well-known/jwks.json", getJWKS) // public keys to verify the tokens from /login
+}
+
+// registerEventRoutes adds the event routes below root, scoped to the tenant chosen by middlewares.Tenant.
+func registerEventRoutes(root *gin.RouterGroup) {
+	// Scopes comes first, it tells Authenticate what an API key needs for these routes
+	eventScopes := middlewares.Scopes(models.ScopeEventsRead, models.ScopeEventsWrite)
+	registrationScopes := middlewares.Scopes(models.ScopeRegistrationsRead, models.ScopeRegistrationsWrite)
+
+	public := root.Group("/")
+	public.Use(eventScopes, middlewares.OptionalAuthenticate, middlewares.Tenant)
+
+	public.GET("/events", getAllEvents)           // Endpoint to get all events, ?category=&tag=&from=&to= filter them
+	public.GET("/events/facets", getEventFacets)  // Endpoint to count events per category and tag
+	public.GET("/events/nearby", getNearbyEvents) // Endpoint to list events around ?lat=&lon=, closest first
+	public.GET("/events/:id", getEventByID)       // Endpoint to get a specific event by ID
+
+	public.GET("/events/:id/comments", getComments)                          // Endpoint to list the Q&A threads, ?cursor=&limit= to page
+	public.GET("/events/:id/comments/:commentId/replies", getCommentReplies) // Endpoint to list the replies of a thread
+
+	public.GET("/events/:id/attachments", getEventAttachments)                                 // Endpoint to list the files of an event
+	public.GET("/events/:id/attachments/:attachmentId", downloadAttachment)                    // Endpoint to download a file, supports Range
+	public.GET("/events/:id/attachments/:attachmentId/thumbnail", downloadAttachmentThumbnail) // Endpoint to get the JPEG thumbnail of an image
+
+	// GraphQL doesn't check scopes, so it takes no API keys
+	graphql := root.Group("/")
+	graphql.Use(middlewares.OptionalAuthenticate, middlewares.Tenant)
+
+	graphql.GET("/graphql", getGraphiQL)  // GraphiQL page to explore the GraphQL API
+	graphql.POST("/graphql", postGraphQL) // Endpoint for GraphQL queries and mutations, login is needed for mutations
 
 	// Gin allows multiple handlers and are executed from left to right
 	// But not a great way if same middleware used for multiple routes
 	// server.POST("/events", middlewares.Authenticate, createEvent) // Endpoint to create a new event
 
 	// relative path can be given
-	authBasedApis := server.Group("/")
-	authBasedApis.Use(middlewares.Authenticate)
+	authBasedApis := root.Group("/")
+	authBasedApis.Use(eventScopes, middlewares.Authenticate, middlewares.Tenant, middlewares.Idempotency)
 
 	// now the authBasedApis group is ued to listen to these paths
 	authBasedApis.POST("/events", createEvent)       // Endpoint to create a new event
 	authBasedApis.PUT("/events/:id", updateEvent)    // Endpoint to update an event
+	authBasedApis.PATCH("/events/:id", patchEvent)   // Endpoint to partially update an event (JSON Merge Patch)
 	authBasedApis.DELETE("/events/:id", deleteEvent) // Endpoint to delete an event
 
-	authBasedApis.POST("/events/:id/register", registerToEvent)       // Endpoint to register for an event
-	authBasedApis.DELETE("/events/:id/register", deleteRegisteration) // endpoint to cancel the registration
-	server.GET("/registrations", getAllRegistrations)
+	authBasedApis.GET("/events/:id/hosts", getEventHosts)              // Endpoint to list the hosts of an event
+	authBasedApis.POST("/events/:id/hosts", inviteEventHost)           // Endpoint to invite a co-host or checker
+	authBasedApis.POST("/events/:id/hosts/accept", acceptHostInvite)   // Endpoint to accept a host invite
+	authBasedApis.DELETE("/events/:id/hosts/:userId", removeEventHost) // Endpoint to remove a host
+	authBasedApis.POST("/events/:id/transfer", transferEventOwnership) // Endpoint to hand the event to another host
+
+	authBasedApis.DELETE("/events/:id/attachments/:attachmentId", deleteAttachment) // Endpoint to delete a file
+
+	authBasedApis.POST("/events/:id/feedback", submitFeedback)        // Endpoint for attendees to rate the event once it is over
+	authBasedApis.GET("/events/:id/feedback/me", getMyFeedback)       // Endpoint to read back one's own feedback
+	authBasedApis.GET("/events/:id/feedback", getEventFeedback)       // Endpoint for hosts to read the feedback, ?format=csv to export
+	authBasedApis.GET("/events/:id/feedback/stats", getFeedbackStats) // Endpoint for hosts to see the average, distribution and response rate
+
+	authBasedApis.POST("/events/:id/comments", createComment)                   // Endpoint for hosts and attendees to ask or answer (markdown)
+	authBasedApis.PATCH("/events/:id/comments/:commentId", updateComment)       // Endpoint for the author to edit a comment
+	authBasedApis.DELETE("/events/:id/comments/:commentId", deleteComment)      // Endpoint for the author to delete a comment
+	authBasedApis.POST("/events/:id/comments/:commentId/hide", hideComment)     // Endpoint for hosts to hide a comment
+	authBasedApis.POST("/events/:id/comments/:commentId/unhide", unhideComment) // Endpoint for hosts to show a hidden comment again
+	authBasedApis.POST("/events/:id/comments/:commentId/pin", pinComment)       // Endpoint for hosts to pin a thread on top
+	authBasedApis.POST("/events/:id/comments/:commentId/unpin", unpinComment)   // Endpoint for hosts to unpin a thread
+
+	authBasedApis.GET("/events/:id/invites", getInvites)                // Endpoint for the owner to list the invites
+	authBasedApis.DELETE("/events/:id/invites/:inviteId", revokeInvite) // Endpoint for the owner to revoke an invite
+	authBasedApis.PUT("/events/:id/access-code", setAccessCode)         // Endpoint for the owner to set the access code
+	authBasedApis.DELETE("/events/:id/access-code", deleteAccessCode)   // Endpoint for the owner to remove the access code
+
+	// uploads skip Idempotency, it would read the whole body before the size limit
+	uploadApis := root.Group("/")
+	uploadApis.Use(eventScopes, middlewares.Authenticate, middlewares.Tenant)
+
+	uploadApis.POST("/events/:id/attachments", uploadAttachment) // Endpoint to upload an image or PDF (multipart field "file")
+
+	// invite tokens skip it too, the stored response would keep them in plain text
+	secretApis := root.Group("/")
+	secretApis.Use(eventScopes, middlewares.Authenticate, middlewares.Tenant)
+
+	secretApis.POST("/events/:id/invites", createInvite) // Endpoint for the owner to create an invite link token
+
+	registrationApis := root.Group("/")
+	registrationApis.Use(registrationScopes, middlewares.Authenticate, middlewares.Tenant, middlewares.Idempotency)
+
+	registrationApis.POST("/events/:id/register", registerToEvent)       // Endpoint to register for an event
+	registrationApis.GET("/events/:id/register", getMyRegistration)      // Endpoint to see the own registration and its history
+	registrationApis.DELETE("/events/:id/register", deleteRegisteration) // endpoint to cancel the registration
+	registrationApis.POST("/events/:id/access", redeemAccess)            // Endpoint to get into a private event with an invite token or the access code
 
-	server.GET("/users", getAllUsers)
-	server.POST("/signup", userSignup) // Endpoint to sign up for users
-	server.POST("/login", userLogin)   // Endpoint  to login the user
+	registrationApis.GET("/events/:id/attendees", getEventAttendees)                   // Endpoint for hosts to list the attendees, ?format=csv to export, ?status= to filter
+	registrationApis.DELETE("/events/:id/attendees/:userId", removeEventAttendee)      // Endpoint for hosts to remove an attendee
+	registrationApis.GET("/events/:id/attendees/:userId/history", getAttendeeHistory)  // Endpoint for hosts to see the status changes of a registration
+	registrationApis.POST("/events/:id/attendees/:userId/approve", approveAttendee)    // Endpoint for the owner to approve a pending registration
+	registrationApis.POST("/events/:id/attendees/:userId/reject", rejectAttendee)      // Endpoint for the owner to reject a pending registration
+	registrationApis.POST("/events/:id/attendees/:userId/check-in", checkInAttendee)   // Endpoint for hosts to mark an attendee as attended
+	registrationApis.POST("/events/:id/attendees/:userId/no-show", markAttendeeNoShow) // Endpoint for hosts to mark a no-show
 }
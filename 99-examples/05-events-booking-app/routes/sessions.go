@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"errors"
+	"events-booking/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// sessionClient is stored with the session, so the user can tell their devices apart.
+func sessionClient(c *gin.Context) models.SessionClient {
+	return models.SessionClient{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
+}
+
+func getMySessions(c *gin.Context) {
+	sessions, err := models.GetUserSessions(c.GetInt64("userId"), c.GetInt64("sessionId"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not retrieve the sessions.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"sessions": sessions})
+}
+
+func deleteMySession(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Could not parse the session ID", "error": err.Error()})
+		return
+	}
+
+	err = models.RevokeSession(c.GetInt64("userId"), id)
+	if errors.Is(err, models.ErrSessionNotFound) {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Session not found"})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not log out the session.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Session logged out"})
+}
+
+// deleteAllMySessions logs out everywhere, ?keep_current=true spares the session of the request.
+func deleteAllMySessions(c *gin.Context) {
+	var keep int64
+	if c.Query("keep_current") == "true" {
+		keep = c.GetInt64("sessionId")
+	}
+
+	err := models.RevokeOtherSessions(c.GetInt64("userId"), keep)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not log out the sessions.", "error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Logged out everywhere"})
+}
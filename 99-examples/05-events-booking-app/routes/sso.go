@@ -0,0 +1,106 @@
+package routes
+
+import (
+	"crypto/subtle"
+	"errors"
+	"events-booking/models"
+	"events-booking/sso"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ssoProvider is nil when no OIDC provider is configured.
+var ssoProvider *sso.Provider
+
+// ssoTrustsMFA skips our second factor on SSO logins, the provider is trusted to ask for its own
+var ssoTrustsMFA bool
+
+// how long a user has to finish the login at the provider
+const ssoLoginTTL = 10 * time.Minute
+
+// the state is also kept in a cookie, so the callback only works in the browser that started the login
+const ssoStateCookie = "oidc_state"
+
+// EnableSSO turns on the SSO login. Users with TOTP turned on still need their
+// code after it, unless trustMFA is set.
+func EnableSSO(provider *sso.Provider, trustMFA bool) {
+	ssoProvider = provider
+	ssoTrustsMFA = trustMFA
+}
+
+// startSSOLogin redirects the browser to the provider, ?login_hint= pre-fills the email there.
+func startSSOLogin(c *gin.Context) {
+	if ssoProvider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Single sign-on is not configured."})
+		return
+	}
+
+	login, url, err := ssoProvider.Start(c.Query("login_hint"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not start the login.", "error": err.Error()})
+		return
+	}
+
+	err = models.SaveOIDCLogin(login, ssoLoginTTL)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not start the login.", "error": err.Error()})
+		return
+	}
+
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(ssoStateCookie, login.State, int(ssoLoginTTL.Seconds()), "/auth/oidc", "", c.Request.TLS != nil, true)
+	c.Redirect(http.StatusFound, url)
+}
+
+// finishSSOLogin is the redirect URL registered at the provider. It answers like /login.
+func finishSSOLogin(c *gin.Context) {
+	if ssoProvider == nil {
+		c.JSON(http.StatusNotFound, gin.H{"message": "Single sign-on is not configured."})
+		return
+	}
+
+	// the login is used up either way
+	c.SetCookie(ssoStateCookie, "", -1, "/auth/oidc", "", c.Request.TLS != nil, true)
+
+	if providerError := c.Query("error"); providerError != "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "The provider did not sign you in.", "error": providerError})
+		return
+	}
+
+	state := c.Query("state")
+	cookieState, _ := c.Cookie(ssoStateCookie)
+	if state == "" || subtle.ConstantTimeCompare([]byte(state), []byte(cookieState)) != 1 {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The login was started in another browser or has expired. Please try again."})
+		return
+	}
+
+	login, err := models.TakeOIDCLogin(state)
+	if errors.Is(err, models.ErrOIDCLoginNotFound) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "The login has expired. Please try again."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not finish the login.", "error": err.Error()})
+		return
+	}
+
+	identity, err := ssoProvider.Finish(c.Request.Context(), *login, c.Query("code"))
+	if err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"message": "Could not verify the login with the provider.", "error": err.Error()})
+		return
+	}
+
+	user, err := models.ProvisionOIDCUser(*identity)
+	if errors.Is(err, models.ErrEmailNotVerified) {
+		c.JSON(http.StatusForbidden, gin.H{"message": "The provider has not verified your email, so the account can't be linked."})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not set up the user.", "error": err.Error()})
+		return
+	}
+
+	respondWithLogin(c, user.Id, user.Email, !ssoTrustsMFA)
+}
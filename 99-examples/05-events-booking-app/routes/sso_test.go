@@ -0,0 +1,165 @@
+package routes
+
+import (
+	"context"
+	"events-booking/db"
+	"events-booking/sso"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// enableTestSSO points the SSO routes at a provider started for the test.
+func enableTestSSO(t *testing.T) *testOIDCProvider {
+	t.Helper()
+
+	p := newTestOIDCProvider(t, oidcUser{})
+	provider, err := sso.New(context.Background(), sso.Config{
+		Issuer:       p.URL,
+		ClientID:     testOIDCClientID,
+		ClientSecret: testOIDCClientSecret,
+		RedirectURL:  "http://localhost/auth/oidc/callback",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	EnableSSO(provider, false)
+	t.Cleanup(func() { EnableSSO(nil, false) })
+	return p
+}
+
+// startSSO runs the login up to the provider's redirect back to us and returns
+// the callback URL with the state cookie the browser would send along.
+func startSSO(t *testing.T) (*url.URL, *http.Cookie) {
+	t.Helper()
+
+	w, _ := request(t, http.MethodGet, "/auth/oidc/login", "", nil, nil)
+	if w.Code != http.StatusFound {
+		t.Fatalf("login answered %d: %s", w.Code, w.Body)
+	}
+	var stateCookie *http.Cookie
+	for _, cookie := range w.Result().Cookies() {
+		if cookie.Name == ssoStateCookie {
+			stateCookie = cookie
+		}
+	}
+	if stateCookie == nil {
+		t.Fatal("login set no state cookie")
+	}
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(w.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("the provider answered %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return callback, stateCookie
+}
+
+func finishSSO(t *testing.T, callback *url.URL, stateCookie *http.Cookie) (*httptest.ResponseRecorder, map[string]any) {
+	t.Helper()
+
+	var header http.Header
+	if stateCookie != nil {
+		header = http.Header{"Cookie": {stateCookie.Name + "=" + stateCookie.Value}}
+	}
+	return request(t, http.MethodGet, callback.RequestURI(), "", nil, header)
+}
+
+func TestSSOLogin(t *testing.T) {
+	p := enableTestSSO(t)
+
+	token := signupAndLogin(t, "linked@example.com")
+	_, me := request(t, http.MethodGet, "/me", token, nil, nil)
+	existingID := me["id"]
+	signupAndLogin(t, "unverified@example.com")
+
+	tests := []struct {
+		name   string
+		user   oidcUser
+		nonce  string // forged into the ID token
+		finish func(t *testing.T, callback *url.URL, stateCookie *http.Cookie) (*httptest.ResponseRecorder, map[string]any)
+		// the user the login ends up as, nil for a new one
+		wantUserID any
+		wantStatus int
+		wantLinked bool // the provider's user is linked afterwards
+	}{
+		{name: "new user", user: oidcUser{"new", "new@example.com", true}, wantStatus: http.StatusOK, wantLinked: true},
+		{name: "verified email of an existing account", user: oidcUser{"linked", "linked@example.com", true},
+			wantUserID: existingID, wantStatus: http.StatusOK, wantLinked: true},
+		{name: "unverified email of an existing account", user: oidcUser{"unverified", "unverified@example.com", false},
+			wantStatus: http.StatusForbidden},
+		{name: "unverified email of a new account", user: oidcUser{"unverified-new", "unverified-new@example.com", false},
+			wantStatus: http.StatusForbidden},
+		{name: "nonce mismatch", user: oidcUser{"nonce", "nonce@example.com", true}, nonce: "forged",
+			wantStatus: http.StatusUnauthorized},
+		{name: "state mismatch", user: oidcUser{"state", "state@example.com", true},
+			finish: func(t *testing.T, callback *url.URL, stateCookie *http.Cookie) (*httptest.ResponseRecorder, map[string]any) {
+				stateCookie.Value = "another-login"
+				return finishSSO(t, callback, stateCookie)
+			},
+			wantStatus: http.StatusBadRequest},
+		{name: "no state cookie", user: oidcUser{"cookie", "cookie@example.com", true},
+			finish: func(t *testing.T, callback *url.URL, stateCookie *http.Cookie) (*httptest.ResponseRecorder, map[string]any) {
+				return finishSSO(t, callback, nil)
+			},
+			wantStatus: http.StatusBadRequest},
+		{name: "replayed callback", user: oidcUser{"replay", "replay@example.com", true},
+			finish: func(t *testing.T, callback *url.URL, stateCookie *http.Cookie) (*httptest.ResponseRecorder, map[string]any) {
+				w, _ := finishSSO(t, callback, stateCookie)
+				if w.Code != http.StatusOK {
+					t.Fatalf("the first callback answered %d: %s", w.Code, w.Body)
+				}
+				// the state is used up, even with the cookie the browser still has
+				return finishSSO(t, callback, stateCookie)
+			},
+			wantStatus: http.StatusBadRequest, wantLinked: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p.signIn(tt.user)
+			p.forgeNonce(tt.nonce)
+			finish := tt.finish
+			if finish == nil {
+				finish = finishSSO
+			}
+
+			callback, stateCookie := startSSO(t)
+			w, answer := finish(t, callback, stateCookie)
+			if w.Code != tt.wantStatus {
+				t.Fatalf("callback answered %d, want %d: %s", w.Code, tt.wantStatus, w.Body)
+			}
+
+			var links int
+			err := db.DB.QueryRow(`SELECT COUNT(*) FROM user_identities WHERE subject = ?`, tt.user.Subject).Scan(&links)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantLinked != (links == 1) {
+				t.Errorf("%d links of the provider's user, linked %v", links, tt.wantLinked)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			if answer["user"] != tt.user.Email {
+				t.Errorf("logged in as %v, want %s", answer["user"], tt.user.Email)
+			}
+			_, me := request(t, http.MethodGet, "/me", answer["token"].(string), nil, nil)
+			if tt.wantUserID != nil && me["id"] != tt.wantUserID {
+				t.Errorf("logged in as user %v, want %v", me["id"], tt.wantUserID)
+			}
+		})
+	}
+}
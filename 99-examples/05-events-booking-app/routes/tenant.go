@@ -0,0 +1,25 @@
+package routes
+
+import (
+	events "events-booking/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// activeOrg is the organization selected by the Tenant middleware, nil outside any organization.
+func activeOrg(c *gin.Context) *events.Organization {
+	org, ok := c.Get("org")
+	if !ok {
+		return nil
+	}
+	return org.(*events.Organization)
+}
+
+// activeOrgID scopes model queries to the active organization.
+func activeOrgID(c *gin.Context) *int64 {
+	org := activeOrg(c)
+	if org == nil {
+		return nil
+	}
+	return &org.ID
+}
@@ -1,6 +1,7 @@
 package routes
 
 import (
+	"errors"
 	users "events-booking/models"
 	"events-booking/utils"
 	"net/http"
@@ -18,6 +19,10 @@ func userSignup(c *gin.Context) {
 	}
 
 	err = user.Save()
+	if errors.Is(err, utils.ErrWeakPassword) {
+		c.JSON(http.StatusBadRequest, gin.H{"message": "Please choose a stronger password.", "error": err.Error()})
+		return
+	}
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not register the user. Please try again later.", "error": err.Error()})
 		return
@@ -40,13 +45,39 @@ func userLogin(c *gin.Context) {
 		return
 	}
 
-	token, err := utils.GenerateJwtToken(user.Email, user.Id)
+	respondWithLogin(c, user.Id, user.Email, true)
+}
+
+// respondWithLogin answers with the JWT of a user whose first factor was checked,
+// or with an mfa_token when checkMFA is set and the user has two-factor authentication on.
+func respondWithLogin(c *gin.Context, userId int64, email string, checkMFA bool) {
+	if checkMFA {
+		mfaEnabled, err := users.MFAEnabled(userId)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not log in.", "error": err.Error()})
+			return
+		}
+
+		// the first factor alone is not enough, the login finishes at /login/mfa
+		if mfaEnabled {
+			mfaToken, err := users.StartMFAChallenge(userId)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not log in.", "error": err.Error()})
+				return
+			}
+
+			c.JSON(http.StatusOK, gin.H{"message": "Enter the code from your authenticator app or a recovery code.", "mfa_required": true, "mfa_token": mfaToken})
+			return
+		}
+	}
+
+	token, err := users.IssueToken(userId, sessionClient(c))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"message": "Could not create a token.", "error": err})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": "User Logged in Successfully", "user": user.Email, "token": token})
+	c.JSON(http.StatusOK, gin.H{"message": "User Logged in Successfully", "user": email, "token": token})
 }
 
 func getAllUsers(c *gin.Context) {
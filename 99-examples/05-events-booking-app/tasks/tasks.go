@@ -0,0 +1,51 @@
+// Package tasks holds the handlers of the background jobs queued by the models.
+package tasks
+
+import (
+	"context"
+	"events-booking/jobs"
+	"events-booking/models"
+	"events-booking/notify"
+	"fmt"
+	"time"
+)
+
+// Register wires every job kind to its handler.
+func Register(pool *jobs.Pool, notifier notify.Notifier) {
+	pool.Handle(models.JobRegistrationConfirmation, sendRegistrationConfirmation(notifier))
+}
+
+func sendRegistrationConfirmation(notifier notify.Notifier) jobs.Handler {
+	return func(ctx context.Context, job *jobs.Job) error {
+		var payload models.RegistrationJob
+		err := job.Decode(&payload)
+		if err != nil {
+			return err
+		}
+
+		wanted, err := models.WantsNotification(payload.UserID, models.NotifyRegistrationConfirmation)
+		if err != nil || !wanted {
+			return err
+		}
+
+		user, err := models.GetUserByID(payload.UserID)
+		if err != nil {
+			return err
+		}
+
+		event, err := models.GetEventByID(payload.EventID)
+		if err != nil {
+			return err
+		}
+
+		return notifier.Notify(ctx, notify.Notification{
+			Kind:    models.NotifyRegistrationConfirmation,
+			UserID:  user.Id,
+			Email:   user.Email,
+			EventID: event.ID,
+			Subject: fmt.Sprintf("You're registered for %s", event.Name),
+			Body:    fmt.Sprintf("See you at %s on %s.", event.Location, event.DateTime.Format(time.RFC1123)),
+			SentAt:  time.Now().UTC(),
+		})
+	}
+}
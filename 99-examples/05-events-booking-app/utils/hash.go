@@ -1,13 +1,166 @@
 package utils
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
 
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Password hashing algorithms, argon2id is the stronger one
+const (
+	HashArgon2id = "argon2id"
+	HashBcrypt   = "bcrypt"
+)
+
+// PasswordHashing is set once at startup by ConfigurePasswordHashing.
+type PasswordHashing struct {
+	Algorithm  string
+	BcryptCost int
+
+	// argon2id, memory in KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+}
+
+// the OWASP minimum for argon2id, used until ConfigurePasswordHashing is called
+var passwordHashing = PasswordHashing{
+	Algorithm:         HashArgon2id,
+	BcryptCost:        12,
+	Argon2Memory:      19 * 1024,
+	Argon2Iterations:  2,
+	Argon2Parallelism: 1,
+}
+
+const (
+	argon2SaltLength = 16
+	argon2KeyLength  = 32
+)
+
+func ConfigurePasswordHashing(cfg PasswordHashing) error {
+	if cfg.Algorithm != HashArgon2id && cfg.Algorithm != HashBcrypt {
+		return fmt.Errorf("unknown password hash %q, use %s or %s", cfg.Algorithm, HashArgon2id, HashBcrypt)
+	}
+	if cfg.BcryptCost < bcrypt.MinCost || cfg.BcryptCost > bcrypt.MaxCost {
+		return fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	if cfg.Argon2Memory < 8*uint32(cfg.Argon2Parallelism) || cfg.Argon2Iterations < 1 || cfg.Argon2Parallelism < 1 {
+		return errors.New("argon2 needs at least 1 iteration, 1 thread and 8 KiB of memory per thread")
+	}
+	// every login needs the memory, more than 4 GiB is a typo (or a negative number)
+	if cfg.Argon2Memory > 4*1024*1024 || cfg.Argon2Iterations > 100 {
+		return errors.New("argon2 allows at most 4 GiB of memory and 100 iterations")
+	}
+
+	passwordHashing = cfg
+	return nil
+}
+
+// HashNewPassword hashes with the configured algorithm and parameters.
 func HashNewPassword(password string) (string, error) {
-	bytes, err := bcrypt.GenerateFromPassword([]byte(password), 14)
-	return string(bytes), err
+	if passwordHashing.Algorithm == HashBcrypt {
+		bytes, err := bcrypt.GenerateFromPassword([]byte(password), passwordHashing.BcryptCost)
+		return string(bytes), err
+	}
+
+	salt := make([]byte, argon2SaltLength)
+	_, err := rand.Read(salt)
+	if err != nil {
+		return "", err
+	}
+
+	params := argon2Params{
+		memory:      passwordHashing.Argon2Memory,
+		iterations:  passwordHashing.Argon2Iterations,
+		parallelism: passwordHashing.Argon2Parallelism,
+	}
+	key := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, argon2KeyLength)
+
+	// the PHC string format, the parameters travel with the hash
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s", argon2.Version,
+		params.memory, params.iterations, params.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
 }
 
+// CheckValidHashPassword accepts bcrypt and argon2id hashes, whatever is configured.
 func CheckValidHashPassword(password string, hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		params, salt, key, err := parseArgon2Hash(hashedPassword)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(password), salt, params.iterations, params.memory, params.parallelism, uint32(len(key)))
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
+
 	err := bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
 	return err == nil
 }
+
+// PasswordNeedsRehash reports whether the hash is weaker than what is configured:
+// bcrypt while argon2id is configured, or lower cost, memory, iterations or threads.
+// A stronger hash is left as it is.
+func PasswordNeedsRehash(hashedPassword string) bool {
+	if strings.HasPrefix(hashedPassword, "$argon2id$") {
+		params, _, _, err := parseArgon2Hash(hashedPassword)
+		if err != nil {
+			return true
+		}
+		if passwordHashing.Algorithm != HashArgon2id {
+			return false
+		}
+		return params.memory < passwordHashing.Argon2Memory ||
+			params.iterations < passwordHashing.Argon2Iterations ||
+			params.parallelism < passwordHashing.Argon2Parallelism
+	}
+
+	if passwordHashing.Algorithm == HashArgon2id {
+		return true
+	}
+
+	cost, err := bcrypt.Cost([]byte(hashedPassword))
+	return err != nil || cost < passwordHashing.BcryptCost
+}
+
+type argon2Params struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func parseArgon2Hash(hashedPassword string) (argon2Params, []byte, []byte, error) {
+	var params argon2Params
+	var version int
+
+	parts := strings.Split(hashedPassword, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, errors.New("invalid argon2id hash")
+	}
+
+	_, err := fmt.Sscanf(parts[2], "v=%d", &version)
+	if err != nil || version != argon2.Version {
+		return params, nil, nil, errors.New("unsupported argon2 version")
+	}
+
+	_, err = fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.memory, &params.iterations, &params.parallelism)
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	salt, err := base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil {
+		return params, nil, nil, err
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil {
+		return params, nil, nil, err
+	}
+
+	return params, salt, key, nil
+}
@@ -7,56 +7,108 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// should be secure key.
-const secretKey = "GangadharIsShaktimaan"
+// JWTConfig is set once at startup by ConfigureJWT.
+type JWTConfig struct {
+	Keys     *Keyset
+	Issuer   string
+	Audience string
+	Leeway   time.Duration // clock skew allowed when checking exp and nbf
+}
+
+var jwtConfig JWTConfig
+
+// TokenLifetime is how long a JWT from GenerateJwtToken is valid.
+const TokenLifetime = time.Hour
+
+func ConfigureJWT(cfg JWTConfig) {
+	jwtConfig = cfg
+}
+
+// JWKS returns the public keys verifiers need for the tokens of this server.
+func JWKS() []JWK {
+	if jwtConfig.Keys == nil {
+		return []JWK{}
+	}
+	return jwtConfig.Keys.JWKS()
+}
+
+// TokenClaims is what VerifyJwtToken reads from a valid token.
+type TokenClaims struct {
+	UserID    int64
+	SessionID int64 // the row in sessions, which can be revoked before the token expires
+}
 
-func GenerateJwtToken(email string, userId int64) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+func GenerateJwtToken(email string, userId int64, sessionId int64) (string, error) {
+	if jwtConfig.Keys == nil {
+		return "", errors.New("JWT keys are not configured")
+	}
+	key := jwtConfig.Keys.active
+
+	now := time.Now()
+	token := jwt.NewWithClaims(key.method, jwt.MapClaims{
 		"email": email,
 		"uid":   userId,
-		"exp":   time.Now().Add(time.Hour).Unix(),
+		"sid":   sessionId,
+		"iss":   jwtConfig.Issuer,
+		"aud":   jwtConfig.Audience,
+		"iat":   now.Unix(),
+		"nbf":   now.Unix(),
+		"exp":   now.Add(TokenLifetime).Unix(),
 	})
+	// verifiers pick the key from the JWKS by kid
+	token.Header["kid"] = key.kid
 
-	// byte slice needed even though SignedSteing says any {}interface
-	return token.SignedString([]byte(secretKey))
+	return token.SignedString(key.private)
 }
 
-func VerifyJwtToken(token string) (int64, error) {
-	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
-		// HMAC is the parent of HS256 method
-		_, ok := t.Method.(*jwt.SigningMethodHMAC)
+// VerifyJwtToken checks the signature and claims, whether the token was revoked
+// is up to the caller (models.AuthenticateToken).
+func VerifyJwtToken(token string) (*TokenClaims, error) {
+	if jwtConfig.Keys == nil {
+		return nil, errors.New("JWT keys are not configured")
+	}
 
+	parsedToken, err := jwt.Parse(token, func(t *jwt.Token) (any, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, ok := jwtConfig.Keys.keys[kid]
 		if !ok {
+			return nil, errors.New("Unknown signing key.")
+		}
+
+		// the algorithm comes from our key, never from the token
+		if t.Method.Alg() != key.method.Alg() {
 			return nil, errors.New("Siging Method not correct.")
 		}
 
-		// since we converted the key into byte slice in GenerateJwtToken:21
-		return []byte(secretKey), nil
-	})
+		return key.public, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodEdDSA.Alg()}),
+		jwt.WithIssuer(jwtConfig.Issuer),
+		jwt.WithAudience(jwtConfig.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(jwtConfig.Leeway),
+	)
 	if err != nil {
-		// userId returned as 0.
-		return 0, errors.New("Could not parse the token")
+		return nil, errors.New("Could not parse the token")
 	}
 
 	if !parsedToken.Valid {
-		return 0, errors.New("Invalid Token")
+		return nil, errors.New("Invalid Token")
 	}
 
 	claims, ok := parsedToken.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, errors.New("Could not parse claims")
+		return nil, errors.New("Could not parse claims")
 	}
 
 	// JWT Claims store it as float64, need to convert
-	exp, ok := claims["exp"].(float64)
-	if !time.Now().Add(-time.Hour).Before(time.Unix(int64(exp), 0)) {
-		// token should not be beofre one hr from now
-		return 0, errors.New("Token is expired")
+	uid, ok := claims["uid"].(float64)
+	if !ok {
+		return nil, errors.New("Could not parse claims")
 	}
 
-	// email = claims["email"].(string)
-	// JWT Claims store it as float64, need to convert
-	userId := int64(claims["uid"].(float64))
+	// tokens from before sessions have none and are refused by the caller
+	sid, _ := claims["sid"].(float64)
 
-	return userId, nil
+	return &TokenClaims{UserID: int64(uid), SessionID: int64(sid)}, nil
 }
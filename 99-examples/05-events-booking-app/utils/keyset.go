@@ -0,0 +1,187 @@
+package utils
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"math/big"
+	"os"
+	"slices"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Keyset holds the key tokens are signed with and the keys of tokens still
+// accepted. To rotate, add the new key as active and move the old one to the
+// retiring keys until the tokens it signed have expired, then drop it.
+type Keyset struct {
+	active signingKey
+	keys   map[string]signingKey // by kid, the active key included
+}
+
+type signingKey struct {
+	kid     string
+	method  jwt.SigningMethod
+	private crypto.Signer // nil for retiring keys loaded from a public key
+	public  crypto.PublicKey
+}
+
+// JWK is one public key of the JWKS document.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+
+	// Ed25519
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+// LoadKeyset reads the active private key and the retiring keys (private or
+// public) from PEM files. RSA keys sign with RS256, Ed25519 keys with EdDSA.
+func LoadKeyset(activePath string, retiringPaths []string) (*Keyset, error) {
+	active, err := readKey(activePath)
+	if err != nil {
+		return nil, err
+	}
+	if active.private == nil {
+		return nil, fmt.Errorf("%s: the active key needs the private key", activePath)
+	}
+
+	ks := &Keyset{active: active, keys: map[string]signingKey{active.kid: active}}
+
+	for _, path := range retiringPaths {
+		key, err := readKey(path)
+		if err != nil {
+			return nil, err
+		}
+		// only the public half is ever used for a retiring key
+		key.private = nil
+		ks.keys[key.kid] = key
+	}
+
+	return ks, nil
+}
+
+// GenerateKeyset creates a throwaway Ed25519 key for local development,
+// tokens stop working when the process restarts.
+func GenerateKeyset() (*Keyset, error) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := newSigningKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Keyset{active: key, keys: map[string]signingKey{key.kid: key}}, nil
+}
+
+// JWKS returns the public keys for /.well-known/jwks.json, the active one first.
+func (ks *Keyset) JWKS() []JWK {
+	jwks := []JWK{ks.active.jwk()}
+	for _, kid := range slices.Sorted(maps.Keys(ks.keys)) {
+		if kid != ks.active.kid {
+			jwks = append(jwks, ks.keys[kid].jwk())
+		}
+	}
+	return jwks
+}
+
+func readKey(path string) (signingKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return signingKey{}, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	var parsed any
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		err = fmt.Errorf("unsupported PEM block %q", block.Type)
+	}
+	if err != nil {
+		return signingKey{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	key, err := newSigningKey(parsed)
+	if err != nil {
+		return signingKey{}, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return key, nil
+}
+
+func newSigningKey(parsed any) (signingKey, error) {
+	var key signingKey
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key = signingKey{method: jwt.SigningMethodRS256, private: k, public: &k.PublicKey}
+	case *rsa.PublicKey:
+		key = signingKey{method: jwt.SigningMethodRS256, public: k}
+	case ed25519.PrivateKey:
+		key = signingKey{method: jwt.SigningMethodEdDSA, private: k, public: k.Public()}
+	case ed25519.PublicKey:
+		key = signingKey{method: jwt.SigningMethodEdDSA, public: k}
+	default:
+		return signingKey{}, errors.New("only RSA and Ed25519 keys are supported")
+	}
+
+	if rsaKey, ok := key.public.(*rsa.PublicKey); ok && rsaKey.N.BitLen() < 2048 {
+		return signingKey{}, errors.New("RSA keys need at least 2048 bits")
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.public)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	// the kid follows the key, so the same file always gets the same kid
+	sum := sha256.Sum256(der)
+	key.kid = base64.RawURLEncoding.EncodeToString(sum[:12])
+
+	return key, nil
+}
+
+func (k signingKey) jwk() JWK {
+	jwk := JWK{Kid: k.kid, Use: "sig", Alg: k.method.Alg()}
+
+	switch public := k.public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+		jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = base64.RawURLEncoding.EncodeToString(public)
+	}
+
+	return jwk
+}
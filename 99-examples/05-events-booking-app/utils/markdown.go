@@ -0,0 +1,138 @@
+package utils
+
+import (
+	"html"
+	"net/url"
+	"strings"
+)
+
+// RenderMarkdown turns the basic markdown of comments into HTML that is safe to
+// show as is: paragraphs, line breaks, "- " lists, ``` code blocks, `code`,
+// **bold**, *italic* and [links](https://...). Everything else is escaped, so
+// raw HTML in the source only ever shows up as text.
+func RenderMarkdown(src string) string {
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
+	var out strings.Builder
+
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case strings.HasPrefix(trimmed, "```"):
+			// a code block runs to the closing fence or the end of the text
+			var code []string
+			for i++; i < len(lines) && !strings.HasPrefix(strings.TrimSpace(lines[i]), "```"); i++ {
+				code = append(code, lines[i])
+			}
+			i++
+			out.WriteString("<pre><code>" + html.EscapeString(strings.Join(code, "\n")) + "</code></pre>")
+
+		case isListItem(trimmed):
+			out.WriteString("<ul>")
+			for ; i < len(lines) && isListItem(strings.TrimSpace(lines[i])); i++ {
+				out.WriteString("<li>" + renderInline(strings.TrimSpace(lines[i])[2:]) + "</li>")
+			}
+			out.WriteString("</ul>")
+
+		default:
+			var paragraph []string
+			for ; i < len(lines); i++ {
+				t := strings.TrimSpace(lines[i])
+				if t == "" || strings.HasPrefix(t, "```") || isListItem(t) {
+					break
+				}
+				paragraph = append(paragraph, renderInline(t))
+			}
+			out.WriteString("<p>" + strings.Join(paragraph, "<br>") + "</p>")
+		}
+	}
+
+	return out.String()
+}
+
+func isListItem(line string) bool {
+	return strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")
+}
+
+// renderInline handles the markup inside a line. Unclosed markers stay as text.
+func renderInline(s string) string {
+	var out strings.Builder
+
+	for i := 0; i < len(s); {
+		rest := s[i:]
+
+		switch {
+		case rest[0] == '`':
+			end := strings.IndexByte(rest[1:], '`')
+			if end > 0 {
+				out.WriteString("<code>" + html.EscapeString(rest[1:1+end]) + "</code>")
+				i += end + 2
+				continue
+			}
+
+		case strings.HasPrefix(rest, "**"):
+			end := strings.Index(rest[2:], "**")
+			if end > 0 {
+				out.WriteString("<strong>" + renderInline(rest[2:2+end]) + "</strong>")
+				i += end + 4
+				continue
+			}
+
+		case rest[0] == '*' || rest[0] == '_':
+			end := strings.IndexByte(rest[1:], rest[0])
+			if end > 0 && rest[1] != ' ' {
+				out.WriteString("<em>" + renderInline(rest[1:1+end]) + "</em>")
+				i += end + 2
+				continue
+			}
+
+		case rest[0] == '[':
+			text, link, n, ok := parseLink(rest)
+			if ok {
+				out.WriteString(`<a href="` + html.EscapeString(link) + `" rel="nofollow noopener noreferrer">` + renderInline(text) + "</a>")
+				i += n
+				continue
+			}
+		}
+
+		out.WriteString(html.EscapeString(rest[:1]))
+		i++
+	}
+
+	return out.String()
+}
+
+// parseLink reads [text](url) at the start of s and returns how many bytes it
+// took. Only http, https and mailto links are accepted, javascript: and the
+// like stay as text.
+func parseLink(s string) (string, string, int, bool) {
+	closeText := strings.Index(s, "](")
+	if closeText < 1 {
+		return "", "", 0, false
+	}
+	closeURL := strings.IndexByte(s[closeText+2:], ')')
+	if closeURL < 1 {
+		return "", "", 0, false
+	}
+
+	text, link := s[1:closeText], s[closeText+2:closeText+2+closeURL]
+	u, err := url.Parse(link)
+	if err != nil || strings.ContainsAny(link, " \"'<>") {
+		return "", "", 0, false
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https":
+		if u.Host == "" {
+			return "", "", 0, false
+		}
+	case "mailto":
+	default:
+		return "", "", 0, false
+	}
+
+	return text, link, closeText + 3 + closeURL, true
+}
@@ -0,0 +1,45 @@
+package utils
+
+import "testing"
+
+func TestRenderMarkdown(t *testing.T) {
+	const rel = ` rel="nofollow noopener noreferrer"`
+
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{"paragraphs and line breaks", "a\nb\n\nc", "<p>a<br>b</p><p>c</p>"},
+		{"list", "- one\n* *two*", "<ul><li>one</li><li><em>two</em></li></ul>"},
+		{"bold", "**bold**", "<p><strong>bold</strong></p>"},
+		{"unclosed marker", "**bold", "<p>**bold</p>"},
+		{"https link", "[x](https://example.com/a?b=1)", `<p><a href="https://example.com/a?b=1"` + rel + ">x</a></p>"},
+		{"mailto link", "[m](mailto:a@example.com)", `<p><a href="mailto:a@example.com"` + rel + ">m</a></p>"},
+
+		// nothing the commenter writes may turn into markup or script
+		{"raw script", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>"},
+		{"raw img handler", "<img src=x onerror=alert(1)>", "<p>&lt;img src=x onerror=alert(1)&gt;</p>"},
+		{"javascript link", "[x](javascript:alert(1))", "<p>[x](javascript:alert(1))</p>"},
+		{"mixed case javascript link", "[x](JavaScript:alert(1))", "<p>[x](JavaScript:alert(1))</p>"},
+		{"data link", "[x](data:text/html,<b>hi</b>)", "<p>[x](data:text/html,&lt;b&gt;hi&lt;/b&gt;)</p>"},
+		{"protocol relative link", "[x](//evil.example)", "<p>[x](//evil.example)</p>"},
+		{"link without host", "[x](https:///path)", "<p>[x](https:///path)</p>"},
+		{"attribute breakout", `[x](https://a.example/" onmouseover="alert(1))`, "<p>[x](https://a.example/&#34; onmouseover=&#34;alert(1))</p>"},
+		{"html in link text", "[<b>x</b>](https://a.example)", `<p><a href="https://a.example"` + rel + ">&lt;b&gt;x&lt;/b&gt;</a></p>"},
+		{"html in bold", "**<i>x</i>**", "<p><strong>&lt;i&gt;x&lt;/i&gt;</strong></p>"},
+		{"html in list", "- <b>x</b>", "<ul><li>&lt;b&gt;x&lt;/b&gt;</li></ul>"},
+		{"html in inline code", "`<script>`", "<p><code>&lt;script&gt;</code></p>"},
+		{"html in code block", "```\n<script>alert(1)</script>\n```", "<pre><code>&lt;script&gt;alert(1)&lt;/script&gt;</code></pre>"},
+		{"unclosed code block", "```\n<script>", "<pre><code>&lt;script&gt;</code></pre>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := RenderMarkdown(tt.src)
+			if got != tt.want {
+				t.Errorf("RenderMarkdown(%q)\n got %q\nwant %q", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package utils
+
+import "encoding/json"
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to the target document.
+// Keys set to null in the patch are removed, objects are merged recursively
+// and every other value replaces the one in the target.
+func MergePatch(target []byte, patch []byte) ([]byte, error) {
+	var patchValue any
+	err := json.Unmarshal(patch, &patchValue)
+	if err != nil {
+		return nil, err
+	}
+
+	var targetValue any
+	if len(target) > 0 {
+		err = json.Unmarshal(target, &targetValue)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(mergeValue(targetValue, patchValue))
+}
+
+func mergeValue(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		// a patch that is not an object replaces the whole target
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	for key, value := range patchObject {
+		if value == nil {
+			delete(targetObject, key)
+			continue
+		}
+		targetObject[key] = mergeValue(targetObject[key], value)
+	}
+
+	return targetObject
+}
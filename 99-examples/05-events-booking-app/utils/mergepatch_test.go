@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestMergePatch(t *testing.T) {
+	// the examples of RFC 7396 appendix A, plus an empty target
+	tests := []struct {
+		target string
+		patch  string
+		want   string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+		{``, `{"a":1,"b":null}`, `{"a":1}`},
+	}
+
+	for _, tt := range tests {
+		got, err := MergePatch([]byte(tt.target), []byte(tt.patch))
+		if err != nil {
+			t.Errorf("MergePatch(%s, %s) failed: %v", tt.target, tt.patch, err)
+			continue
+		}
+		if !jsonEqual(t, got, []byte(tt.want)) {
+			t.Errorf("MergePatch(%s, %s) = %s, want %s", tt.target, tt.patch, got, tt.want)
+		}
+	}
+}
+
+func TestMergePatchInvalidJSON(t *testing.T) {
+	_, err := MergePatch([]byte(`{"a":"b"}`), []byte(`{"a":`))
+	if err == nil {
+		t.Error("MergePatch accepted a broken patch")
+	}
+
+	_, err = MergePatch([]byte(`{"a":`), []byte(`{"a":"b"}`))
+	if err == nil {
+		t.Error("MergePatch accepted a broken target")
+	}
+}
+
+// jsonEqual compares documents regardless of key order and spacing.
+func jsonEqual(t *testing.T, a []byte, b []byte) bool {
+	t.Helper()
+
+	var av, bv any
+	err := json.Unmarshal(a, &av)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	err = json.Unmarshal(b, &bv)
+	if err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	return reflect.DeepEqual(av, bv)
+}
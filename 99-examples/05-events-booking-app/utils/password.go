@@ -0,0 +1,62 @@
+package utils
+
+import (
+	_ "embed"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrWeakPassword is wrapped by every policy violation, the message says which rule failed.
+var ErrWeakPassword = errors.New("password does not meet the policy")
+
+// bcrypt only looks at the first 72 bytes, longer passwords are refused
+// whatever the algorithm so switching to bcrypt never breaks them
+const maxPasswordBytes = 72
+
+// one common or breached password per line, compared case-insensitively
+//
+//go:embed common-passwords.txt
+var commonPasswordsFile string
+
+var commonPasswords = func() map[string]bool {
+	passwords := map[string]bool{}
+	for _, line := range strings.Split(commonPasswordsFile, "\n") {
+		line = strings.ToLower(strings.TrimSpace(line))
+		if line != "" {
+			passwords[line] = true
+		}
+	}
+	return passwords
+}()
+
+var passwordMinLength = 10
+
+// SetPasswordMinLength changes the minimum length in characters, 8 is the lowest allowed.
+func SetPasswordMinLength(length int) {
+	passwordMinLength = max(length, 8)
+}
+
+// ValidatePassword checks a new password of the user with the given email.
+func ValidatePassword(password string, email string) error {
+	if utf8.RuneCountInString(password) < passwordMinLength {
+		return fmt.Errorf("%w: use at least %d characters", ErrWeakPassword, passwordMinLength)
+	}
+	if len(password) > maxPasswordBytes {
+		return fmt.Errorf("%w: use at most %d bytes", ErrWeakPassword, maxPasswordBytes)
+	}
+
+	lower := strings.ToLower(password)
+	if commonPasswords[lower] {
+		return fmt.Errorf("%w: it is on a list of common or breached passwords", ErrWeakPassword)
+	}
+
+	email = strings.ToLower(email)
+	localPart, _, _ := strings.Cut(email, "@")
+	if email != "" && (strings.Contains(lower, email) || len(localPart) >= 3 && strings.Contains(lower, localPart)) {
+		return fmt.Errorf("%w: it must not contain your email", ErrWeakPassword)
+	}
+
+	return nil
+}
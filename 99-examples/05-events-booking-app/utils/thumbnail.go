@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif" // registers the GIF decoder
+	"image/jpeg"
+	_ "image/png" // registers the PNG decoder
+)
+
+// decoding allocates 4 bytes per pixel, bigger images are refused before that
+const maxThumbnailSourcePixels = 40_000_000
+
+var ErrImageTooLarge = errors.New("image has too many pixels")
+
+// Thumbnail scales a JPEG, PNG or GIF down to fit into maxSize x maxSize and
+// returns it as a JPEG. Transparent areas become white. Smaller images keep their size.
+func Thumbnail(data []byte, maxSize int) ([]byte, int, int, error) {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	if config.Width*config.Height > maxThumbnailSourcePixels {
+		return nil, 0, 0, ErrImageTooLarge
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxSize || height > maxSize {
+		if width >= height {
+			width, height = maxSize, max(1, height*maxSize/width)
+		} else {
+			width, height = max(1, width*maxSize/height), maxSize
+		}
+	}
+
+	// flatten onto white first, JPEG has no alpha
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, src, bounds.Min, draw.Over)
+
+	var out bytes.Buffer
+	err = jpeg.Encode(&out, downscale(flat, width, height), &jpeg.Options{Quality: 80})
+	if err != nil {
+		return nil, 0, 0, err
+	}
+
+	return out.Bytes(), width, height, nil
+}
+
+// downscale averages the source pixels falling into each target pixel (a box
+// filter), the standard library has no resampling.
+func downscale(src *image.RGBA, width int, height int) *image.RGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := y * srcHeight / height
+		y1 := max((y+1)*srcHeight/height, y0+1)
+
+		for x := 0; x < width; x++ {
+			x0 := x * srcWidth / width
+			x1 := max((x+1)*srcWidth/width, x0+1)
+
+			var r, g, b, n uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += uint64(pixel[0])
+					g += uint64(pixel[1])
+					b += uint64(pixel[2])
+					n++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / n)
+			dst.Pix[offset+1] = uint8(g / n)
+			dst.Pix[offset+2] = uint8(b / n)
+			dst.Pix[offset+3] = 255
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,46 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// the RFC 6238 appendix B key, "12345678901234567890" in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTP(t *testing.T) {
+	// the SHA1 vectors of RFC 6238 appendix B, cut to our 6 digits
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		unix     int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"vector 59", rfc6238Secret, "287082", 59, 1, true},
+		{"vector 1111111109", rfc6238Secret, "081804", 1111111109, 37037036, true},
+		{"vector 1111111111", rfc6238Secret, "050471", 1111111111, 37037037, true},
+		{"vector 1234567890", rfc6238Secret, "005924", 1234567890, 41152263, true},
+		{"vector 2000000000", rfc6238Secret, "279037", 2000000000, 66666666, true},
+		{"vector 20000000000", rfc6238Secret, "353130", 20000000000, 666666666, true},
+		{"lowercase secret", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", "287082", 59, 1, true},
+		{"spaces in the code", rfc6238Secret, "287 082", 59, 1, true},
+		{"previous step", rfc6238Secret, "287082", 89, 1, true},
+		{"next step", rfc6238Secret, "081804", 1111111109 - 30, 37037036, true},
+		{"two steps late", rfc6238Secret, "287082", 119, 0, false},
+		{"wrong code", rfc6238Secret, "287083", 59, 0, false},
+		{"8 digits", rfc6238Secret, "94287082", 59, 0, false},
+		{"too short", rfc6238Secret, "28708", 59, 0, false},
+		{"bad secret", "not base32!", "287082", 59, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok := ValidateTOTP(tt.secret, tt.code, time.Unix(tt.unix, 0))
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("ValidateTOTP(%q, %q, %d) = %d, %v, want %d, %v", tt.secret, tt.code, tt.unix, step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}